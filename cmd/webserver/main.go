@@ -1,24 +1,60 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
+	_ "time/tzdata"
 	"todo-app/internal/handlers"
+	"todo-app/internal/scheduler"
 	"todo-app/internal/storage"
 )
 
 func main() {
-	dbFile := getDBFilePath()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
 
-	dbStorage, err := storage.NewStorage(dbFile)
+	dbStorage, err := openStorage()
 	if err != nil {
 		log.Fatalf("Error initializing database: %v", err)
 	}
 	defer dbStorage.Close()
 
-	handler := &handlers.Handler{Storage: dbStorage}
+	if holidaysFile := os.Getenv("TODO_HOLIDAYS"); holidaysFile != "" {
+		calendar, err := scheduler.LoadCalendar(holidaysFile)
+		if err != nil {
+			log.Fatalf("Error loading holidays calendar: %v", err)
+		}
+		scheduler.SetCalendar(calendar)
+	}
+
+	if tz := os.Getenv("TODO_TZ"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			log.Fatalf("Error loading timezone: %v", err)
+		}
+		handlers.DefaultLocation = loc
+	}
+
+	var clock scheduler.Clock = scheduler.SystemClock{}
+	if date := os.Getenv("TODO_NOW"); date != "" {
+		// Режим имитации даты: сервер считает сегодняшним днём date.
+		clock, err = scheduler.NewDateClock(date, handlers.DefaultLocation)
+		if err != nil {
+			log.Fatalf("Error parsing TODO_NOW, expected YYYYMMDD: %v", err)
+		}
+		log.Printf("Simulating date %s", date)
+	}
+
 	webDir := "./web"
 
 	port := os.Getenv("TODO_PORT")
@@ -26,43 +62,108 @@ func main() {
 		port = "7540"
 	}
 
-	fileServer := http.FileServer(http.Dir(webDir))
-
-	http.Handle("/", fileServer)
-	http.HandleFunc("/api/nextdate", handlers.NextDateHandler)
-	http.HandleFunc("/api/tasks", handler.TasksHandler)
-
-	http.HandleFunc("/api/task", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			handlers.AddTaskHandler(dbStorage).ServeHTTP(w, r)
-		case http.MethodGet:
-			handlers.GetTaskHandler(dbStorage).ServeHTTP(w, r)
-		case http.MethodPut:
-			handlers.UpdateTaskHandler(dbStorage).ServeHTTP(w, r)
-		case http.MethodDelete:
-			handlers.DeleteTaskHandler(dbStorage).ServeHTTP(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
-
-	http.HandleFunc("/api/task/done", func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost:
-			handlers.DoneTaskHandler(dbStorage).ServeHTTP(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	auth, err := newAuth(dbStorage, clock)
+	if err != nil {
+		log.Fatalf("Error initializing authentication: %v", err)
+	}
+
+	router := handlers.NewRouter(dbStorage, clock, webDir, auth)
 
 	log.Printf("Starting server on port %s...", port)
-	err = http.ListenAndServe(":"+port, nil)
+	err = http.ListenAndServe(":"+port, router)
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
+// newAuth включает учётные записи, если задан TODO_ACCOUNTS=true, иначе —
+// вход по паролю из TODO_PASSWORD.
+func newAuth(db storage.TaskRepository, clock scheduler.Clock) (*handlers.Auth, error) {
+	accounts, _ := strconv.ParseBool(os.Getenv("TODO_ACCOUNTS"))
+	if !accounts {
+		auth := handlers.NewAuth(os.Getenv("TODO_PASSWORD"), clock)
+		if auth.Enabled() {
+			log.Println("Password authentication is enabled")
+		}
+		return auth, nil
+	}
+
+	users, ok := db.(storage.UserRepository)
+	if !ok {
+		return nil, fmt.Errorf("storage does not support user accounts")
+	}
+	secret := os.Getenv("TODO_SECRET")
+	if secret == "" {
+		log.Println("TODO_SECRET is not set, tokens will expire on restart")
+	}
+	log.Println("User accounts are enabled")
+	return handlers.NewAccountsAuth(users, secret, clock)
+}
+
+// openStorage выбирает хранилище: TODO_STORAGE=memory — задачи в памяти,
+// TODO_DATABASE_URL — PostgreSQL, иначе — файл SQLite.
+func openStorage() (storage.TaskRepository, error) {
+	switch kind := os.Getenv("TODO_STORAGE"); kind {
+	case "":
+	case "memory":
+		log.Println("Using in-memory storage, tasks are lost on restart")
+		return storage.NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown TODO_STORAGE: %s", kind)
+	}
+
+	if dsn := os.Getenv("TODO_DATABASE_URL"); dsn != "" {
+		log.Println("Using PostgreSQL storage")
+		return storage.NewPostgresStorage(dsn)
+	}
+	return storage.NewStorage(getDBFilePath())
+}
+
+// runMigrate выполняет подкоманду "migrate [-dry-run]": применяет
+// недостающие миграции или, с -dry-run, только печатает их SQL.
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "print pending migrations without applying them")
+	flags.Parse(args)
+
+	if os.Getenv("TODO_STORAGE") == "memory" {
+		return fmt.Errorf("in-memory storage has no schema to migrate")
+	}
+
+	dialect, source := storage.DialectSQLite, getDBFilePath()
+	if dsn := os.Getenv("TODO_DATABASE_URL"); dsn != "" {
+		dialect, source = storage.DialectPostgres, dsn
+	}
+
+	db, err := storage.OpenDatabase(dialect, source)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pending, err := storage.PendingMigrations(db, dialect)
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		if len(pending) == 0 {
+			fmt.Println("Schema is up to date")
+		}
+		for _, m := range pending {
+			fmt.Printf("-- %s\n%s\n", m.Name, m.SQL)
+		}
+		return nil
+	}
+
+	// Migrate вызывается и без новых миграций: для базы, созданной до их
+	// появления, он заполняет таблицу schema_version.
+	if err := storage.Migrate(db, dialect); err != nil {
+		return err
+	}
+	fmt.Printf("Applied %d migration(s)\n", len(pending))
+	return nil
+}
+
 func getDBFilePath() string {
 	dbFile := os.Getenv("TODO_DBFILE")
 	if dbFile == "" {
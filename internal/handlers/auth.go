@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// tokenTTL — срок действия токена после входа.
+const tokenTTL = 8 * time.Hour
+
+// minPasswordLength — минимальная длина пароля учётной записи.
+const minPasswordLength = 6
+
+var errInvalidToken = errors.New("invalid token")
+
+// Auth проверяет пароль из TODO_PASSWORD и выдаёт токены JWT (HS256).
+// Ключ подписи выводится из пароля, поэтому после смены пароля старые
+// токены перестают действовать.
+//
+// В режиме учётных записей (NewAccountsAuth) пользователи регистрируются
+// сами, а токен содержит id пользователя: обработчики работают только
+// с его задачами.
+type Auth struct {
+	passwordHash string
+	users        storage.UserRepository
+	key          []byte
+	clock        scheduler.Clock
+}
+
+// NewAuth возвращает проверку входа по паролю. Пустой пароль отключает
+// аутентификацию: Middleware пропускает все запросы.
+func NewAuth(password string, clock scheduler.Clock) *Auth {
+	if clock == nil {
+		clock = scheduler.SystemClock{}
+	}
+	if password == "" {
+		return &Auth{clock: clock}
+	}
+
+	sum := sha256.Sum256([]byte(password))
+	key := sha256.Sum256([]byte("todo-app token key:" + password))
+	return &Auth{
+		passwordHash: hex.EncodeToString(sum[:]),
+		key:          key[:],
+		clock:        clock,
+	}
+}
+
+// NewAccountsAuth возвращает вход по учётным записям из users. Токены
+// подписываются ключом из secret; если он пуст, ключ генерируется
+// случайно и токены перестают действовать после перезапуска.
+func NewAccountsAuth(users storage.UserRepository, secret string, clock scheduler.Clock) (*Auth, error) {
+	if clock == nil {
+		clock = scheduler.SystemClock{}
+	}
+
+	key := make([]byte, sha256.Size)
+	if secret == "" {
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	} else {
+		sum := sha256.Sum256([]byte("todo-app token key:" + secret))
+		key = sum[:]
+	}
+	return &Auth{users: users, key: key, clock: clock}, nil
+}
+
+// Enabled сообщает, задан ли пароль или включены учётные записи.
+func (a *Auth) Enabled() bool {
+	return a != nil && (a.passwordHash != "" || a.users != nil)
+}
+
+// Accounts сообщает, включены ли учётные записи.
+func (a *Auth) Accounts() bool {
+	return a != nil && a.users != nil
+}
+
+type signInRequest struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+type tokenClaims struct {
+	UserID    int64 `json:"uid,omitempty"`
+	ExpiresAt int64 `json:"exp"`
+}
+
+// SignInHandler проверяет пароль из {"password": "..."} и возвращает
+// {"token": "..."}. Фронтенд сохраняет токен в cookie token.
+// В режиме учётных записей нужен ещё логин: {"login": "...", "password": "..."}.
+func (a *Auth) SignInHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.Enabled() {
+		writeError(w, "Authentication is disabled", http.StatusBadRequest)
+		return
+	}
+
+	var req signInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	var claims tokenClaims
+	if a.Accounts() {
+		user, err := a.users.GetUserByLogin(req.Login)
+		if err != nil && !errors.Is(err, storage.ErrUserNotFound) {
+			writeError(w, "Failed to load user", http.StatusInternalServerError)
+			return
+		}
+		if user == nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)) != nil {
+			writeError(w, "Неверный логин или пароль", http.StatusUnauthorized)
+			return
+		}
+		claims.UserID = user.ID
+	} else {
+		sum := sha256.Sum256([]byte(req.Password))
+		if !hmac.Equal([]byte(hex.EncodeToString(sum[:])), []byte(a.passwordHash)) {
+			writeError(w, "Неверный пароль", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	a.writeToken(w, claims, nil)
+}
+
+// RegisterHandler создаёт учётную запись из {"login": "...", "password": "..."}
+// и сразу выполняет вход: возвращает {"id": ..., "token": "..."}.
+func (a *Auth) RegisterHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !a.Accounts() {
+		writeError(w, "Registration is disabled", http.StatusBadRequest)
+		return
+	}
+
+	var req signInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	req.Login = strings.TrimSpace(req.Login)
+	if req.Login == "" {
+		writeError(w, "Login is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Password) < minPasswordLength {
+		writeError(w, "Password is too short", http.StatusBadRequest)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		// Пароль длиннее 72 байт bcrypt не принимает.
+		writeError(w, "Invalid password", http.StatusBadRequest)
+		return
+	}
+
+	id, err := a.users.AddUser(&storage.User{
+		Login:        req.Login,
+		PasswordHash: string(hash),
+		CreatedAt:    a.clock.Now().UTC().Format(time.RFC3339),
+	})
+	if errors.Is(err, storage.ErrUserExists) {
+		writeError(w, "Логин уже занят", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		writeError(w, "Failed to create user", http.StatusInternalServerError)
+		return
+	}
+
+	a.writeToken(w, tokenClaims{UserID: id}, map[string]any{"id": id})
+}
+
+// writeToken выдаёт токен с claims и добавляет его к полям ответа fields.
+func (a *Auth) writeToken(w http.ResponseWriter, claims tokenClaims, fields map[string]any) {
+	claims.ExpiresAt = a.clock.Now().Add(tokenTTL).Unix()
+	token, err := a.issue(claims)
+	if err != nil {
+		writeError(w, "Failed to create token", http.StatusInternalServerError)
+		return
+	}
+
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	fields["token"] = token
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(fields)
+}
+
+// Middleware пропускает запросы к /api/* только с действующим токеном
+// в cookie token или в заголовке Authorization: Bearer. Вход
+// (/api/signin), регистрация (/api/register) и статические файлы
+// доступны без токена. Id пользователя из токена доступен через UserID.
+func (a *Auth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Enabled() || !strings.HasPrefix(r.URL.Path, "/api/") ||
+			r.URL.Path == "/api/signin" || r.URL.Path == "/api/register" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := a.verifyRequest(r)
+		if err != nil {
+			writeError(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+		if claims.UserID != 0 {
+			r = r.WithContext(context.WithValue(r.Context(), userIDKey{}, claims.UserID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+type userIDKey struct{}
+
+// UserID возвращает id пользователя, выполнившего вход. ok = false, если
+// учётные записи не включены.
+func UserID(ctx context.Context) (id int64, ok bool) {
+	id, ok = ctx.Value(userIDKey{}).(int64)
+	return id, ok
+}
+
+// verifyRequest проверяет токен из заголовка Authorization, а если его нет
+// или он недействителен — из cookie. Устаревшая cookie браузера не мешает
+// клиенту API передать свежий токен в заголовке.
+func (a *Auth) verifyRequest(r *http.Request) (tokenClaims, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if claims, err := a.verify(strings.TrimPrefix(header, "Bearer ")); err == nil {
+			return claims, nil
+		}
+	}
+	cookie, err := r.Cookie("token")
+	if err != nil {
+		return tokenClaims{}, errInvalidToken
+	}
+	return a.verify(cookie.Value)
+}
+
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+func (a *Auth) issue(c tokenClaims) (string, error) {
+	claims, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(claims)
+	return unsigned + "." + a.sign(unsigned), nil
+}
+
+func (a *Auth) verify(token string) (tokenClaims, error) {
+	var claims tokenClaims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != tokenHeader {
+		return claims, errInvalidToken
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(a.sign(parts[0]+"."+parts[1]))) {
+		return claims, errInvalidToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, errInvalidToken
+	}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return claims, errInvalidToken
+	}
+
+	if a.clock.Now().Unix() >= claims.ExpiresAt {
+		return claims, errInvalidToken
+	}
+	if a.Accounts() != (claims.UserID != 0) {
+		return claims, errInvalidToken
+	}
+	return claims, nil
+}
+
+func (a *Auth) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"todo-app/internal/storage"
+)
+
+// HistoryHandler возвращает историю выполнений задачи из параметра id,
+// от последнего выполнения к первому. История выполненных и удалённых
+// разовых задач тоже доступна.
+func HistoryHandler(db storage.TaskRepository) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		taskIDStr := r.URL.Query().Get("id")
+		if taskIDStr == "" {
+			http.Error(w, `{"error":"Task ID is required"}`, http.StatusBadRequest)
+			return
+		}
+
+		taskID, err := strconv.ParseInt(taskIDStr, 10, 64)
+		if err != nil {
+			http.Error(w, `{"error":"Invalid task ID"}`, http.StatusBadRequest)
+			return
+		}
+
+		completions, err := db.GetCompletions(taskID)
+		if err != nil {
+			http.Error(w, `{"error":"Failed to load task history"}`, http.StatusInternalServerError)
+			return
+		}
+
+		if len(completions) == 0 {
+			exists, err := db.TaskExists(taskID)
+			if err != nil || !exists {
+				http.Error(w, `{"error":"Task not found"}`, http.StatusNotFound)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"completions": completions,
+		})
+	}
+}
@@ -1,18 +1,24 @@
 package handlers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
+	"strconv"
 	"todo-app/internal/scheduler"
 )
 
-func NextDateHandler(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) NextDateHandler(w http.ResponseWriter, r *http.Request) {
 	nowStr := r.FormValue("now")
 	dateStr := r.FormValue("date")
 	repeat := r.FormValue("repeat")
 
-	now, err := time.Parse("20060102", nowStr)
+	if nowStr == "" {
+		http.Error(w, "Invalid 'now' date format", http.StatusBadRequest)
+		return
+	}
+
+	now, err := parseNow(r, h.Clock, nowStr)
 	if err != nil {
 		http.Error(w, "Invalid 'now' date format", http.StatusBadRequest)
 		return
@@ -26,3 +32,48 @@ func NextDateHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write([]byte(nextDate))
 }
+
+const (
+	defaultNextDatesCount = 5
+	maxNextDatesCount     = 100
+)
+
+func (h *Handler) NextDatesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	nowStr := r.FormValue("now")
+	dateStr := r.FormValue("date")
+	repeat := r.FormValue("repeat")
+
+	now, err := parseNow(r, h.Clock, nowStr)
+	if err != nil {
+		writeError(w, "Invalid 'now' date format", http.StatusBadRequest)
+		return
+	}
+	if dateStr == "" {
+		dateStr = now.Format("20060102")
+	}
+
+	count := defaultNextDatesCount
+	if countStr := r.FormValue("count"); countStr != "" {
+		count, err = strconv.Atoi(countStr)
+		if err != nil || count <= 0 || count > maxNextDatesCount {
+			writeError(w, fmt.Sprintf("Invalid count value, expected 1..%d", maxNextDatesCount), http.StatusBadRequest)
+			return
+		}
+	}
+
+	dates, err := scheduler.NextDates(now, dateStr, repeat, count)
+	if err != nil {
+		writeError(w, fmt.Sprintf("Error calculating next dates: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dates": dates,
+	})
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+// SkipTaskHandler пропускает текущее повторение задачи: дата задачи
+// переходит на следующее повторение, а пропущенная дата запоминается
+// как исключение. Правило повторения не меняется.
+func SkipTaskHandler(db storage.TaskRepository, clock scheduler.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		task, ok := repeatingTask(w, r, db)
+		if !ok {
+			return
+		}
+
+		now, err := requestNow(r, clock)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		exceptions, err := db.GetExceptions(task.ID)
+		if err != nil {
+			http.Error(w, `{"error":"Failed to load task exceptions"}`, http.StatusInternalServerError)
+			return
+		}
+
+		baseDate, except := applyExceptions(task.Date, exceptions)
+		except = append(except, task.Date)
+
+		nextDate, err := scheduler.NextDateExcept(now, baseDate, task.Repeat, except)
+		if errors.Is(err, scheduler.ErrRuleEnded) {
+			writeError(w, "No occurrences left to skip to", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeError(w, fmt.Sprintf("Failed to calculate next date: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		if err := db.AddException(task.ID, baseDate, ""); writeAccessError(w, err) {
+			return
+		} else if err != nil {
+			http.Error(w, `{"error":"Failed to save task exception"}`, http.StatusInternalServerError)
+			return
+		}
+		if err := resolveMove(db, task, baseDate); err != nil {
+			http.Error(w, `{"error":"Failed to save task exception"}`, http.StatusInternalServerError)
+			return
+		}
+
+		if err := db.UpdateTaskDate(task.ID, nextDate, consumeRepeat(task.Repeat)); err != nil {
+			http.Error(w, `{"error":"Failed to update task date"}`, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}
+}
+
+// MoveTaskHandler переносит текущее повторение задачи на дату из
+// параметра date. Исходная дата запоминается, чтобы следующее повторение
+// считалось по прежнему графику.
+func MoveTaskHandler(db storage.TaskRepository, clock scheduler.Clock) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+			return
+		}
+
+		task, ok := repeatingTask(w, r, db)
+		if !ok {
+			return
+		}
+
+		now, err := requestNow(r, clock)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Дата задаётся так же, как при создании задачи: 2026-10-18, +3d, "завтра".
+		date, err := scheduler.ParseDate(r.URL.Query().Get("date"), now)
+		if err != nil {
+			http.Error(w, `{"error":"Invalid date format, expected YYYYMMDD"}`, http.StatusBadRequest)
+			return
+		}
+
+		exceptions, err := db.GetExceptions(task.ID)
+		if err != nil {
+			http.Error(w, `{"error":"Failed to load task exceptions"}`, http.StatusInternalServerError)
+			return
+		}
+
+		// При повторном переносе исключение остаётся привязанным
+		// к исходной дате повторения.
+		origDate, _ := applyExceptions(task.Date, exceptions)
+
+		if err := db.AddException(task.ID, origDate, date); writeAccessError(w, err) {
+			return
+		} else if err != nil {
+			http.Error(w, `{"error":"Failed to save task exception"}`, http.StatusInternalServerError)
+			return
+		}
+
+		if err := db.UpdateTaskDate(task.ID, date, task.Repeat); err != nil {
+			http.Error(w, `{"error":"Failed to update task date"}`, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}
+}
+
+// repeatingTask загружает повторяющуюся задачу по параметру id и при
+// ошибке сама отправляет ответ.
+func repeatingTask(w http.ResponseWriter, r *http.Request, db storage.TaskRepository) (*storage.Task, bool) {
+	taskIDStr := r.URL.Query().Get("id")
+	if taskIDStr == "" {
+		http.Error(w, `{"error":"Task ID is required"}`, http.StatusBadRequest)
+		return nil, false
+	}
+
+	taskID, err := strconv.ParseInt(taskIDStr, 10, 64)
+	if err != nil {
+		http.Error(w, `{"error":"Invalid task ID"}`, http.StatusBadRequest)
+		return nil, false
+	}
+
+	task, err := db.GetTaskByID(taskID)
+	if err != nil {
+		http.Error(w, `{"error":"Task not found"}`, http.StatusNotFound)
+		return nil, false
+	}
+
+	if task.Repeat == "" {
+		http.Error(w, `{"error":"Task is not repeating"}`, http.StatusBadRequest)
+		return nil, false
+	}
+
+	return task, true
+}
+
+// resolveMove отмечает перенос повторения baseDate использованным, если
+// текущая дата задачи — перенесённое повторение. Иначе, когда график
+// снова придёт на дату переноса, она опять считалась бы перенесённой.
+func resolveMove(db storage.TaskRepository, task *storage.Task, baseDate string) error {
+	if baseDate == task.Date {
+		return nil
+	}
+	return db.ResolveMove(task.ID, baseDate)
+}
+
+// applyExceptions возвращает дату, от которой считается следующее
+// повторение (исходную дату, если текущее повторение было перенесено),
+// и список исключённых дат.
+func applyExceptions(date string, exceptions []storage.Exception) (string, []string) {
+	baseDate := date
+	except := make([]string, 0, len(exceptions))
+	for _, e := range exceptions {
+		if e.MovedTo == date {
+			baseDate = e.Date
+		}
+		except = append(except, e.Date)
+	}
+	return baseDate, except
+}
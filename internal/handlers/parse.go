@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo-app/internal/scheduler"
+)
+
+// ParseHandler переводит фразу из параметра text ("завтра",
+// "каждый понедельник", "every 2 weeks") в дату и правило повторения.
+func (h *Handler) ParseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	now, err := parseNow(r, h.Clock, r.FormValue("now"))
+	if err != nil {
+		writeError(w, "Invalid 'now' date format", http.StatusBadRequest)
+		return
+	}
+
+	phrase, err := scheduler.ParsePhrase(r.FormValue("text"), now)
+	if err != nil {
+		writeError(w, "Could not recognize date or repeat rule", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(phrase)
+}
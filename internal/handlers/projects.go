@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+// ProjectHandler обслуживает проекты — общие списки задач — и их
+// участников. Проекты доступны только при входе по учётной записи.
+type ProjectHandler struct {
+	Projects storage.ProjectRepository
+	Users    storage.UserRepository
+	Clock    scheduler.Clock
+}
+
+type projectRequest struct {
+	Name string `json:"name"`
+}
+
+type memberRequest struct {
+	ProjectID string `json:"project_id"`
+	Login     string `json:"login"`
+	Role      string `json:"role"`
+}
+
+// ProjectsHandler по GET возвращает проекты пользователя с его ролью,
+// по POST создаёт проект {"name": "..."}, владельцем которого становится
+// пользователь.
+func (h *ProjectHandler) ProjectsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserID(r.Context())
+	if !ok {
+		writeError(w, "Projects require user accounts", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		projects, err := h.Projects.GetProjects(userID)
+		if err != nil {
+			writeError(w, "Failed to load projects", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"projects": projects})
+
+	case http.MethodPost:
+		var req projectRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" {
+			writeError(w, "Name is required", http.StatusBadRequest)
+			return
+		}
+
+		now, err := requestNow(r, h.Clock)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		id, err := h.Projects.AddProject(&storage.Project{
+			Name:      req.Name,
+			CreatedAt: now.Format(time.RFC3339),
+		}, userID)
+		if err != nil {
+			writeError(w, "Failed to create project", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TaskResponse{ID: id})
+
+	default:
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// MembersHandler управляет участниками проекта:
+//   - GET ?project_id= — список участников, доступен всем участникам;
+//   - POST {"project_id", "login", "role"} — добавляет участника или меняет
+//     его роль, доступен владельцу;
+//   - DELETE ?project_id=&user_id= — исключает участника; доступен
+//     владельцу, а остальные участники могут так выйти из проекта.
+func (h *ProjectHandler) MembersHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserID(r.Context())
+	if !ok {
+		writeError(w, "Projects require user accounts", http.StatusBadRequest)
+		return
+	}
+
+	var req memberRequest
+	switch r.Method {
+	case http.MethodGet, http.MethodDelete:
+		req.ProjectID = r.URL.Query().Get("project_id")
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, "Invalid JSON", http.StatusBadRequest)
+			return
+		}
+	default:
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	projectID, err := strconv.ParseInt(req.ProjectID, 10, 64)
+	if err != nil {
+		writeError(w, "Invalid project_id", http.StatusBadRequest)
+		return
+	}
+
+	// Для не участников проекта не существует.
+	role, err := h.Projects.ProjectRole(projectID, userID)
+	if errors.Is(err, storage.ErrProjectNotFound) {
+		writeError(w, "Проект не найден", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeError(w, "Failed to load project", http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		members, err := h.Projects.GetMembers(projectID)
+		if err != nil {
+			writeError(w, "Failed to load project members", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"members": members})
+		return
+
+	case http.MethodPost:
+		if role != storage.RoleOwner {
+			writeError(w, "Только владелец управляет участниками", http.StatusForbidden)
+			return
+		}
+		memberRole, err := storage.ParseRole(req.Role)
+		if err != nil {
+			writeError(w, "Invalid role, expected owner, editor or viewer", http.StatusBadRequest)
+			return
+		}
+		user, err := h.Users.GetUserByLogin(strings.TrimSpace(req.Login))
+		if errors.Is(err, storage.ErrUserNotFound) {
+			writeError(w, "Пользователь не найден", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeError(w, "Failed to load user", http.StatusInternalServerError)
+			return
+		}
+		// Иначе проект может остаться без владельца.
+		if user.ID == userID {
+			writeError(w, "Владелец не может изменить свою роль", http.StatusBadRequest)
+			return
+		}
+		if err := h.Projects.SetMember(projectID, user.ID, memberRole); err != nil {
+			writeError(w, "Failed to save project member", http.StatusInternalServerError)
+			return
+		}
+
+	case http.MethodDelete:
+		memberID, err := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
+		if err != nil {
+			writeError(w, "Invalid user_id", http.StatusBadRequest)
+			return
+		}
+		if memberID == userID && role == storage.RoleOwner {
+			writeError(w, "Владелец не может выйти из проекта", http.StatusBadRequest)
+			return
+		}
+		if memberID != userID && role != storage.RoleOwner {
+			writeError(w, "Только владелец управляет участниками", http.StatusForbidden)
+			return
+		}
+		err = h.Projects.RemoveMember(projectID, memberID)
+		if errors.Is(err, storage.ErrUserNotFound) {
+			writeError(w, "Участник не найден", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			writeError(w, "Failed to remove project member", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+// NewRouter возвращает обработчик всех маршрутов приложения: API поверх
+// хранилища db и статические файлы из webDir. Если auth задан, API
+// доступно только после входа через /api/signin. При входе по учётной
+// записи обработчики работают только с задачами пользователя.
+func NewRouter(db storage.TaskRepository, clock scheduler.Clock, webDir string, auth *Auth) http.Handler {
+	handler := &Handler{Storage: db, Clock: clock}
+	userHandler := func(r *http.Request) *Handler {
+		return &Handler{Storage: userStorage(r, db), Clock: clock}
+	}
+	mux := http.NewServeMux()
+
+	mux.Handle("/", http.FileServer(http.Dir(webDir)))
+	mux.HandleFunc("/api/nextdate", handler.NextDateHandler)
+	mux.HandleFunc("/api/nextdates", handler.NextDatesHandler)
+	mux.HandleFunc("/api/parse", handler.ParseHandler)
+	mux.HandleFunc("/api/tasks", func(w http.ResponseWriter, r *http.Request) {
+		userHandler(r).TasksHandler(w, r)
+	})
+	mux.HandleFunc("/api/search", func(w http.ResponseWriter, r *http.Request) {
+		userHandler(r).SearchHandler(w, r)
+	})
+
+	mux.HandleFunc("/api/task", func(w http.ResponseWriter, r *http.Request) {
+		db := userStorage(r, db)
+		switch r.Method {
+		case http.MethodPost:
+			AddTaskHandler(db, clock).ServeHTTP(w, r)
+		case http.MethodGet:
+			GetTaskHandler(db).ServeHTTP(w, r)
+		case http.MethodPut:
+			UpdateTaskHandler(db, clock).ServeHTTP(w, r)
+		case http.MethodDelete:
+			DeleteTaskHandler(db).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/task/done", func(w http.ResponseWriter, r *http.Request) {
+		db := userStorage(r, db)
+		switch r.Method {
+		case http.MethodPost:
+			DoneTaskHandler(db, clock).ServeHTTP(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("/api/task/history", func(w http.ResponseWriter, r *http.Request) {
+		HistoryHandler(userStorage(r, db)).ServeHTTP(w, r)
+	})
+	mux.HandleFunc("/api/task/skip", func(w http.ResponseWriter, r *http.Request) {
+		SkipTaskHandler(userStorage(r, db), clock).ServeHTTP(w, r)
+	})
+	mux.HandleFunc("/api/task/move", func(w http.ResponseWriter, r *http.Request) {
+		MoveTaskHandler(userStorage(r, db), clock).ServeHTTP(w, r)
+	})
+
+	projects, _ := db.(storage.ProjectRepository)
+	users, _ := db.(storage.UserRepository)
+	if projects != nil && users != nil {
+		projectHandler := &ProjectHandler{Projects: projects, Users: users, Clock: clock}
+		mux.HandleFunc("/api/projects", projectHandler.ProjectsHandler)
+		mux.HandleFunc("/api/project/members", projectHandler.MembersHandler)
+	}
+
+	if auth == nil {
+		return mux
+	}
+	mux.HandleFunc("/api/signin", auth.SignInHandler)
+	mux.HandleFunc("/api/register", auth.RegisterHandler)
+	return auth.Middleware(mux)
+}
+
+// userStorage возвращает хранилище задач пользователя, выполнившего вход
+// по учётной записи, или db, если учётные записи не используются.
+func userStorage(r *http.Request, db storage.TaskRepository) storage.TaskRepository {
+	if userID, ok := UserID(r.Context()); ok {
+		return db.ForOwner(userID)
+	}
+	return db
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"todo-app/internal/storage"
+	"unicode/utf8"
+)
+
+// SearchHandler ищет задачи по названию и комментарию: GET /api/search?q=.
+// Запрос — слова и фразы в двойных кавычках, "*" в конце слова ищет по
+// префиксу. Результаты упорядочены по релевантности, совпадения выделены
+// в полях highlight и snippet. Если у хранилища нет полнотекстового
+// индекса, ищется подстрока без ранжирования.
+func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, "Search query is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			writeError(w, "Invalid limit value", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var tasks []map[string]string
+	err := storage.ErrFullTextUnavailable
+	if searcher, ok := h.Storage.(storage.FullTextSearcher); ok {
+		tasks, err = searcher.FullTextSearch(query, limit)
+	}
+	if errors.Is(err, storage.ErrFullTextUnavailable) {
+		tasks, err = h.substringSearch(query, limit)
+	}
+	if err != nil {
+		log.Printf("Error searching tasks: %v", err)
+		writeError(w, "Error searching tasks", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"tasks": tasks,
+	})
+}
+
+// substringSearch ищет задачи, содержащие все слова и фразы запроса.
+func (h *Handler) substringSearch(query string, limit int) ([]map[string]string, error) {
+	var terms []string
+	for _, term := range storage.SearchTerms(query) {
+		if term = strings.TrimSuffix(term, "*"); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return []map[string]string{}, nil
+	}
+
+	// Хранилище ищет по самому длинному слову, остальные проверяются здесь.
+	longest := terms[0]
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) > utf8.RuneCountInString(longest) {
+			longest = term
+		}
+	}
+	candidates, err := h.Storage.SearchTasks(longest, storage.TaskFilter{}, maxSearchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []map[string]string{}
+	for _, task := range candidates {
+		text := strings.ToLower(task["title"] + "\n" + task["comment"])
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(text, strings.ToLower(term)) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		task["highlight"] = highlightTerms(task["title"], terms)
+		task["snippet"] = highlightTerms(task["comment"], terms)
+		tasks = append(tasks, task)
+		if len(tasks) == limit {
+			break
+		}
+	}
+	return tasks, nil
+}
+
+// maxSearchCandidates ограничивает выборку для поиска без индекса.
+const maxSearchCandidates = 1000
+
+// highlightTerms экранирует текст для HTML и выделяет в нём вхождения
+// слов без учёта регистра.
+func highlightTerms(text string, terms []string) string {
+	lower := []rune(strings.ToLower(text))
+	runes := []rune(text)
+	if len(lower) != len(runes) {
+		return html.EscapeString(text)
+	}
+
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		t := []rune(strings.ToLower(term))
+		for i := 0; i+len(t) <= len(lower); i++ {
+			if string(lower[i:i+len(t)]) == string(t) {
+				for j := i; j < i+len(t); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(storage.HighlightStart)
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString(storage.HighlightEnd)
+		}
+	}
+	return b.String()
+}
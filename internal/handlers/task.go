@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,11 +13,25 @@ import (
 )
 
 type TaskRequest struct {
-	ID      string `json:"id"`
-	Date    string `json:"date"`
-	Title   string `json:"title"`
-	Comment string `json:"comment"`
-	Repeat  string `json:"repeat"`
+	ID    string `json:"id"`
+	Date  string `json:"date"`
+	Title string `json:"title"`
+	// Time, RepeatMode и CatchUp, не переданные при изменении задачи,
+	// сохраняют прежние значения. Пустое время убирает его.
+	Time       *string `json:"time"`
+	Comment    string  `json:"comment"`
+	Repeat     string  `json:"repeat"`
+	RepeatMode *string `json:"repeat_mode"`
+	CatchUp    *string `json:"catchup"`
+	// ProjectID и AssigneeID — id проекта и исполнителя. При изменении
+	// задачи пустое значение оставляет прежние, "0" — убирает.
+	ProjectID  string `json:"project_id"`
+	AssigneeID string `json:"assignee_id"`
+}
+
+// DoneRequest — необязательное тело запроса /api/task/done.
+type DoneRequest struct {
+	Note string `json:"note"`
 }
 
 type TaskResponse struct {
@@ -23,7 +39,7 @@ type TaskResponse struct {
 	Error string `json:"error,omitempty"`
 }
 
-func AddTaskHandler(db *storage.Storage) http.HandlerFunc {
+func AddTaskHandler(db storage.TaskRepository, clock scheduler.Clock) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -42,30 +58,58 @@ func AddTaskHandler(db *storage.Storage) http.HandlerFunc {
 			return
 		}
 
-		now := time.Now().Format("20060102")
+		now, err := requestNow(r, clock)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		today := now.Format("20060102")
 		if task.Date == "" {
-			task.Date = now
+			task.Date = today
 		}
 
-		_, err = time.Parse("20060102", task.Date)
+		// Кроме YYYYMMDD дата может быть задана как 2026-10-18, +3d, today или "завтра".
+		task.Date, err = scheduler.ParseDate(task.Date, now)
 		if err != nil {
 			http.Error(w, `{"error":"Invalid date format, expected YYYYMMDD"}`, http.StatusBadRequest)
 			return
 		}
 
+		if !isValidTime(stringValue(task.Time)) {
+			http.Error(w, `{"error":"Invalid time format, expected HH:MM"}`, http.StatusBadRequest)
+			return
+		}
+
 		if task.Repeat != "" {
-			if task.Repeat[0] == 'm' || task.Repeat[0] == 'w' {
-				http.Error(w, `{"error":"Unsupported repeat type: only daily and yearly repeats are allowed"}`, http.StatusBadRequest)
-				return
+			if _, err := scheduler.ParseRule(task.Repeat); err != nil {
+				// Правило может быть введено словами: "каждый понедельник".
+				repeat, natErr := scheduler.ParseNaturalRepeat(task.Repeat, now)
+				if natErr != nil {
+					writeError(w, fmt.Sprintf("Invalid repeat rule: %v", err), http.StatusBadRequest)
+					return
+				}
+				task.Repeat = repeat
 			}
 		}
 
-		today := time.Now().Format("20060102")
+		repeatMode, err := scheduler.ParseRepeatMode(stringValue(task.RepeatMode))
+		if err != nil {
+			writeError(w, fmt.Sprintf("Invalid repeat mode: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		catchUp, err := scheduler.ParseCatchUp(stringValue(task.CatchUp))
+		if err != nil {
+			writeError(w, fmt.Sprintf("Invalid catch-up policy: %v", err), http.StatusBadRequest)
+			return
+		}
+
 		if task.Date < today {
 			if task.Repeat != "" {
-				nextDate, err := scheduler.NextDate(time.Now(), task.Date, task.Repeat)
+				nextDate, err := scheduler.NextDate(now, task.Date, task.Repeat)
 				if err != nil {
-					http.Error(w, fmt.Sprintf(`{"error":"Invalid repeat rule: %v"}`, err), http.StatusBadRequest)
+					writeError(w, fmt.Sprintf("Invalid repeat rule: %v", err), http.StatusBadRequest)
 					return
 				}
 				task.Date = nextDate
@@ -78,16 +122,28 @@ func AddTaskHandler(db *storage.Storage) http.HandlerFunc {
 			task.Date = today
 		}
 
-		query := `INSERT INTO scheduler (date, title, comment, repeat) VALUES (?, ?, ?, ?)`
-		res, err := db.DB.Exec(query, task.Date, task.Title, task.Comment, task.Repeat)
+		projectID, assigneeID, err := parsePlacement(task, 0, 0)
 		if err != nil {
-			http.Error(w, `{"error":"Failed to insert task"}`, http.StatusInternalServerError)
+			writeError(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		id, err := res.LastInsertId()
+		id, err := db.AddTask(&storage.Task{
+			Date:       task.Date,
+			Time:       stringValue(task.Time),
+			Title:      task.Title,
+			Comment:    task.Comment,
+			Repeat:     task.Repeat,
+			RepeatMode: string(repeatMode),
+			CatchUp:    string(catchUp),
+			ProjectID:  projectID,
+			AssigneeID: assigneeID,
+		})
+		if writeAccessError(w, err) {
+			return
+		}
 		if err != nil {
-			http.Error(w, `{"error":"Failed to retrieve task ID"}`, http.StatusInternalServerError)
+			http.Error(w, `{"error":"Failed to insert task"}`, http.StatusInternalServerError)
 			return
 		}
 
@@ -97,7 +153,7 @@ func AddTaskHandler(db *storage.Storage) http.HandlerFunc {
 	}
 }
 
-func GetTaskHandler(db *storage.Storage) http.HandlerFunc {
+func GetTaskHandler(db storage.TaskRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
@@ -125,11 +181,16 @@ func GetTaskHandler(db *storage.Storage) http.HandlerFunc {
 		w.Header().Set("Content-Type", "application/json")
 
 		response := map[string]interface{}{
-			"id":      strconv.FormatInt(task.ID, 10), // Преобразуем ID в строку
-			"date":    task.Date,
-			"title":   task.Title,
-			"comment": task.Comment,
-			"repeat":  task.Repeat,
+			"id":          strconv.FormatInt(task.ID, 10), // Преобразуем ID в строку
+			"date":        task.Date,
+			"time":        task.Time,
+			"title":       task.Title,
+			"comment":     task.Comment,
+			"repeat":      task.Repeat,
+			"repeat_mode": task.RepeatMode,
+			"catchup":     task.CatchUp,
+			"project_id":  strconv.FormatInt(task.ProjectID, 10),
+			"assignee_id": strconv.FormatInt(task.AssigneeID, 10),
 		}
 
 		if err := json.NewEncoder(w).Encode(response); err != nil {
@@ -138,7 +199,7 @@ func GetTaskHandler(db *storage.Storage) http.HandlerFunc {
 	}
 }
 
-func UpdateTaskHandler(db *storage.Storage) http.HandlerFunc {
+func UpdateTaskHandler(db storage.TaskRepository, clock scheduler.Clock) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPut {
 			http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
@@ -169,30 +230,84 @@ func UpdateTaskHandler(db *storage.Storage) http.HandlerFunc {
 			return
 		}
 
-		now := time.Now().Format("20060102")
+		now, err := requestNow(r, clock)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		if task.Date == "" {
-			task.Date = now
+			task.Date = now.Format("20060102")
 		}
 
 		// Проверка формата даты
-		_, err = time.Parse("20060102", task.Date)
+		task.Date, err = scheduler.ParseDate(task.Date, now)
 		if err != nil {
 			http.Error(w, `{"error":"Invalid date format, expected YYYYMMDD"}`, http.StatusBadRequest)
 			return
 		}
 
-		if task.Repeat != "" && !isValidRepeat(task.Repeat) {
-			http.Error(w, `{"error":"Unsupported repeat type: must start with 'd' or 'y'"}`, http.StatusBadRequest)
+		if !isValidTime(stringValue(task.Time)) {
+			http.Error(w, `{"error":"Invalid time format, expected HH:MM"}`, http.StatusBadRequest)
 			return
 		}
 
-		exists, err := db.TaskExists(taskID)
-		if err != nil || !exists {
+		if task.Repeat != "" {
+			if _, err := scheduler.ParseRule(task.Repeat); err != nil {
+				writeError(w, fmt.Sprintf("Invalid repeat rule: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		repeatMode, err := scheduler.ParseRepeatMode(stringValue(task.RepeatMode))
+		if err != nil {
+			writeError(w, fmt.Sprintf("Invalid repeat mode: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		catchUp, err := scheduler.ParseCatchUp(stringValue(task.CatchUp))
+		if err != nil {
+			writeError(w, fmt.Sprintf("Invalid catch-up policy: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		current, err := db.GetTaskByID(taskID)
+		if err != nil {
 			http.Error(w, `{"error": "Задача не найдена"}`, http.StatusNotFound)
 			return
 		}
 
-		err = db.UpdateTask(taskID, task.Date, task.Title, task.Comment, task.Repeat)
+		projectID, assigneeID, err := parsePlacement(task, current.ProjectID, current.AssigneeID)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if task.Time == nil {
+			task.Time = &current.Time
+		}
+		if task.RepeatMode == nil {
+			repeatMode = scheduler.RepeatMode(current.RepeatMode)
+		}
+		if task.CatchUp == nil {
+			catchUp = scheduler.CatchUp(current.CatchUp)
+		}
+
+		err = db.UpdateTask(&storage.Task{
+			ID:         taskID,
+			Date:       task.Date,
+			Time:       *task.Time,
+			Title:      task.Title,
+			Comment:    task.Comment,
+			Repeat:     task.Repeat,
+			RepeatMode: string(repeatMode),
+			CatchUp:    string(catchUp),
+			ProjectID:  projectID,
+			AssigneeID: assigneeID,
+		})
+		if writeAccessError(w, err) {
+			return
+		}
 		if err != nil {
 			http.Error(w, `{"error": "Задача не найдена"}`, http.StatusNotFound)
 			return
@@ -204,23 +319,7 @@ func UpdateTaskHandler(db *storage.Storage) http.HandlerFunc {
 	}
 }
 
-func isValidRepeat(repeat string) bool {
-	if len(repeat) < 2 {
-		return false
-	}
-
-	if repeat[0] == 'd' || repeat[0] == 'y' {
-		if len(repeat) >= 3 && repeat[1] == ' ' {
-			if _, err := strconv.Atoi(repeat[2:]); err == nil {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-func DoneTaskHandler(db *storage.Storage) http.HandlerFunc {
+func DoneTaskHandler(db storage.TaskRepository, clock scheduler.Clock) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
@@ -239,31 +338,84 @@ func DoneTaskHandler(db *storage.Storage) http.HandlerFunc {
 			return
 		}
 
+		// Тело с заметкой необязательно: фронтенд отправляет пустой запрос.
+		var done DoneRequest
+		if err := json.NewDecoder(r.Body).Decode(&done); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+			return
+		}
+
 		task, err := db.GetTaskByID(taskID)
 		if err != nil {
 			http.Error(w, `{"error":"Task not found"}`, http.StatusNotFound)
 			return
 		}
 
-		if task.Repeat == "" {
-			query := `DELETE FROM scheduler WHERE id = ?`
-			_, err := db.DB.Exec(query, taskID)
-			if err != nil {
-				http.Error(w, `{"error":"Failed to delete task"}`, http.StatusInternalServerError)
-				return
-			}
-		} else {
-			now := time.Now()
-			nextDate, err := scheduler.NextDate(now, task.Date, task.Repeat)
-			if err != nil {
-				http.Error(w, fmt.Sprintf(`{"error":"Failed to calculate next date: %s"}`, err), http.StatusInternalServerError)
-				return
+		now, err := requestNow(r, clock)
+		if err != nil {
+			writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		exceptions, err := db.GetExceptions(taskID)
+		if err != nil {
+			http.Error(w, `{"error":"Failed to load task exceptions"}`, http.StatusInternalServerError)
+			return
+		}
+
+		// Перенесённое повторение отсчитывается от исходной даты,
+		// чтобы перенос не сбивал график.
+		baseDate, except := applyExceptions(task.Date, exceptions)
+
+		// При политике "each" пропущенные повторения выполняются по одному:
+		// следующая дата отсчитывается от даты задачи, а не от now.
+		doneAt := now
+		if scheduler.CatchUp(task.CatchUp) == scheduler.CatchUpEach &&
+			scheduler.RepeatMode(task.RepeatMode) != scheduler.ModeCompletion &&
+			baseDate < now.Format("20060102") {
+			doneAt, _ = time.Parse("20060102", baseDate)
+		}
+
+		// При политике "skip" выполняется повторение, которое показано
+		// в списке задач, а просроченные пропускаются.
+		fromDate, doneDate := baseDate, task.Date
+		if scheduler.CatchUp(task.CatchUp) == scheduler.CatchUpSkip && task.Repeat != "" &&
+			task.Date < now.Format("20060102") {
+			if current, err := scheduler.CurrentDate(now, task.Date, task.Repeat); err == nil && current != task.Date {
+				fromDate, doneDate = current, current
 			}
+		}
+
+		// Для задачи без повторения и для последнего повторения
+		// NextDate возвращает пустую дату — такая задача удаляется.
+		nextDate, err := scheduler.NextDateMode(doneAt, fromDate, task.Repeat, scheduler.RepeatMode(task.RepeatMode), except)
+		if errors.Is(err, scheduler.ErrRuleEnded) {
+			nextDate, err = "", nil
+		}
+		if err != nil {
+			writeError(w, fmt.Sprintf("Failed to calculate next date: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		// Выполнение записывается вместе с переносом или удалением задачи,
+		// чтобы при ошибке в истории не осталось выполнения без переноса.
+		err = db.CompleteTask(storage.Completion{
+			TaskID:      taskID,
+			Date:        doneDate,
+			CompletedAt: now.Format(time.RFC3339),
+			Note:        done.Note,
+		}, nextDate, consumeRepeat(task.Repeat))
+		if writeAccessError(w, err) {
+			return
+		}
+		if err != nil {
+			http.Error(w, `{"error":"Failed to save task completion"}`, http.StatusInternalServerError)
+			return
+		}
 
-			query := `UPDATE scheduler SET date = ? WHERE id = ?`
-			_, err = db.DB.Exec(query, nextDate, taskID)
-			if err != nil {
-				http.Error(w, `{"error":"Failed to update task date"}`, http.StatusInternalServerError)
+		if nextDate != "" {
+			if err := resolveMove(db, task, baseDate); err != nil {
+				http.Error(w, `{"error":"Failed to save task exception"}`, http.StatusInternalServerError)
 				return
 			}
 		}
@@ -274,7 +426,17 @@ func DoneTaskHandler(db *storage.Storage) http.HandlerFunc {
 	}
 }
 
-func DeleteTaskHandler(db *storage.Storage) http.HandlerFunc {
+// consumeRepeat возвращает правило повторения после того, как одно
+// повторение задачи выполнено или пропущено: для правил с "count" счётчик
+// уменьшается.
+func consumeRepeat(repeat string) string {
+	if rule, err := scheduler.ParseRule(repeat); err == nil && rule.Count > 0 {
+		return rule.Consume().String()
+	}
+	return repeat
+}
+
+func DeleteTaskHandler(db storage.TaskRepository) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
 			http.Error(w, `{"error": "Method not allowed"}`, http.StatusMethodNotAllowed)
@@ -294,21 +456,16 @@ func DeleteTaskHandler(db *storage.Storage) http.HandlerFunc {
 		}
 
 		// Удаление задачи из базы данных
-		query := `DELETE FROM scheduler WHERE id = ?`
-		res, err := db.DB.Exec(query, taskID)
-		if err != nil {
-			http.Error(w, `{"error":"Ошибка при удалении задачи"}`, http.StatusInternalServerError)
+		err = db.DeleteTask(taskID)
+		if errors.Is(err, storage.ErrTaskNotFound) {
+			http.Error(w, `{"error":"Задача не найдена"}`, http.StatusNotFound)
 			return
 		}
-
-		rowsAffected, err := res.RowsAffected()
-		if err != nil {
-			http.Error(w, `{"error":"Ошибка при определении количества затронутых строк"}`, http.StatusInternalServerError)
+		if writeAccessError(w, err) {
 			return
 		}
-
-		if rowsAffected == 0 {
-			http.Error(w, `{"error":"Задача не найдена"}`, http.StatusNotFound)
+		if err != nil {
+			http.Error(w, `{"error":"Ошибка при удалении задачи"}`, http.StatusInternalServerError)
 			return
 		}
 
@@ -318,3 +475,61 @@ func DeleteTaskHandler(db *storage.Storage) http.HandlerFunc {
 		w.Write([]byte("{}"))
 	}
 }
+
+// isValidTime проверяет необязательное время задачи в формате HH:MM.
+func isValidTime(s string) bool {
+	if s == "" {
+		return true
+	}
+	_, err := time.Parse("15:04", s)
+	return err == nil && len(s) == len("15:04")
+}
+
+// stringValue возвращает значение необязательного поля запроса.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// parsePlacement возвращает проект и исполнителя задачи из запроса.
+// Незаданные поля сохраняют значения projectID и assigneeID.
+func parsePlacement(task TaskRequest, projectID, assigneeID int64) (int64, int64, error) {
+	var err error
+	if task.ProjectID != "" {
+		if projectID, err = parseOptionalID(task.ProjectID); err != nil {
+			return 0, 0, fmt.Errorf("Invalid project_id: %q", task.ProjectID)
+		}
+	}
+	if task.AssigneeID != "" {
+		if assigneeID, err = parseOptionalID(task.AssigneeID); err != nil {
+			return 0, 0, fmt.Errorf("Invalid assignee_id: %q", task.AssigneeID)
+		}
+	}
+	return projectID, assigneeID, nil
+}
+
+// writeAccessError отвечает на ошибки прав доступа из хранилища и
+// возвращает true, если err — одна из них.
+func writeAccessError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, storage.ErrTaskReadOnly):
+		writeError(w, "Недостаточно прав для изменения задачи", http.StatusForbidden)
+	case errors.Is(err, storage.ErrProjectNotFound):
+		writeError(w, "Проект не найден", http.StatusBadRequest)
+	case errors.Is(err, storage.ErrInvalidAssignee):
+		writeError(w, "Исполнитель не участвует в проекте", http.StatusBadRequest)
+	default:
+		return false
+	}
+	return true
+}
+
+// writeError отправляет ошибку в формате {"error": "..."}, экранируя
+// текст сообщения.
+func writeError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(TaskResponse{Error: message})
+}
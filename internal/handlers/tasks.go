@@ -2,14 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/scheduler"
 	"todo-app/internal/storage"
 )
 
 type Handler struct {
-	Storage *storage.Storage
+	Storage storage.TaskRepository
+	Clock   scheduler.Clock
 }
 
 
@@ -30,7 +37,32 @@ func (h *Handler) TasksHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tasks, err := h.Storage.GetUpcomingTasks(limit)
+	now, err := requestNow(r, h.Clock)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := taskFilter(r)
+	if err != nil {
+		writeError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Строка поиска вида 02.01.2006 выбирает задачи на дату,
+	// любая другая ищется в названии и комментарии.
+	var tasks []map[string]string
+	search := strings.TrimSpace(r.URL.Query().Get("search"))
+	if date, dateErr := time.Parse("02.01.2006", search); dateErr == nil {
+		tasks, err = h.Storage.GetTasksByDate(date.Format("20060102"), filter, limit)
+	} else if search != "" {
+		tasks, err = h.Storage.SearchTasks(search, filter, limit)
+	} else {
+		tasks, err = h.Storage.GetUpcomingTasks(filter, limit)
+		if err == nil {
+			tasks = catchUpTasks(tasks, now, limit)
+		}
+	}
 	if err != nil {
 		log.Printf("Error fetching tasks: %v", err)
 		http.Error(w, "Error fetching tasks", http.StatusInternalServerError)
@@ -46,3 +78,88 @@ func (h *Handler) TasksHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Error encoding response", http.StatusInternalServerError)
 	}
 }
+
+// taskFilter читает фильтры списка задач: project — id проекта,
+// assignee — id исполнителя или "me" для пользователя, выполнившего вход.
+func taskFilter(r *http.Request) (storage.TaskFilter, error) {
+	var filter storage.TaskFilter
+	var err error
+
+	query := r.URL.Query()
+	if filter.ProjectID, err = parseOptionalID(query.Get("project")); err != nil {
+		return filter, fmt.Errorf("invalid project: %q", query.Get("project"))
+	}
+
+	assignee := query.Get("assignee")
+	if assignee == "me" {
+		userID, ok := UserID(r.Context())
+		if !ok {
+			return filter, errors.New("assignee=me requires user accounts")
+		}
+		filter.AssigneeID = userID
+	} else if filter.AssigneeID, err = parseOptionalID(assignee); err != nil {
+		return filter, fmt.Errorf("invalid assignee: %q", assignee)
+	}
+	return filter, nil
+}
+
+// parseOptionalID разбирает необязательный id: пустая строка означает 0.
+func parseOptionalID(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid id: %q", s)
+	}
+	return id, nil
+}
+
+// catchUpTasks применяет к просроченным повторяющимся задачам их политику
+// пропущенных повторений: "skip" показывает задачу на ближайшей дате,
+// "each" — отдельной строкой на каждое пропущенное повторение.
+func catchUpTasks(tasks []map[string]string, now time.Time, limit int) []map[string]string {
+	today := now.Format("20060102")
+	result := make([]map[string]string, 0, len(tasks))
+	for _, task := range tasks {
+		if task["repeat"] == "" || task["date"] >= today {
+			result = append(result, task)
+			continue
+		}
+
+		switch scheduler.CatchUp(task["catchup"]) {
+		case scheduler.CatchUpSkip:
+			if date, err := scheduler.CurrentDate(now, task["date"], task["repeat"]); err == nil {
+				task["date"] = date
+			}
+			result = append(result, task)
+		case scheduler.CatchUpEach:
+			dates, err := scheduler.MissedDates(now, task["date"], task["repeat"], limit)
+			if err != nil {
+				result = append(result, task)
+				continue
+			}
+			for _, date := range dates {
+				occurrence := make(map[string]string, len(task))
+				for k, v := range task {
+					occurrence[k] = v
+				}
+				occurrence["date"] = date
+				result = append(result, occurrence)
+			}
+		default:
+			result = append(result, task)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i]["date"] != result[j]["date"] {
+			return result[i]["date"] < result[j]["date"]
+		}
+		return result[i]["time"] < result[j]["time"]
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+	"todo-app/internal/scheduler"
+)
+
+// DefaultLocation — часовой пояс, в котором считается «сегодня», если
+// запрос не указал свой. Задаётся переменной окружения TODO_TZ.
+var DefaultLocation = time.Local
+
+// requestLocation возвращает часовой пояс из параметра tz или заголовка
+// X-Timezone (например, Europe/Moscow).
+func requestLocation(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		name = r.Header.Get("X-Timezone")
+	}
+	if name == "" {
+		return DefaultLocation, nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone: %s", name)
+	}
+	return loc, nil
+}
+
+// requestNow возвращает текущий момент по часам clock в часовом поясе
+// запроса. Без часов используется системное время.
+func requestNow(r *http.Request, clock scheduler.Clock) (time.Time, error) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if clock == nil {
+		clock = scheduler.SystemClock{}
+	}
+	return clock.Now().In(loc), nil
+}
+
+// parseNow разбирает параметр now запроса в любом формате, который
+// понимает scheduler.ParseDate. Пустое значение означает текущий момент.
+func parseNow(r *http.Request, clock scheduler.Clock, nowStr string) (time.Time, error) {
+	now, err := requestNow(r, clock)
+	if err != nil || nowStr == "" {
+		return now, err
+	}
+
+	date, err := scheduler.ParseDate(nowStr, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse("20060102", date)
+}
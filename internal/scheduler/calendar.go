@@ -0,0 +1,230 @@
+package scheduler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Calendar — производственный календарь: выходные дни, праздники и
+// перенесённые рабочие дни.
+type Calendar struct {
+	holidays map[string]bool
+	workdays map[string]bool
+}
+
+// calendar используется правилами "bd" и сдвигом "shift".
+// По умолчанию рабочими считаются все дни с понедельника по пятницу.
+var calendar = NewCalendar()
+
+// SetCalendar задаёт календарь для всех правил повторения.
+// Вызывается один раз при запуске, до обработки запросов.
+func SetCalendar(c *Calendar) {
+	calendar = c
+}
+
+func NewCalendar() *Calendar {
+	return &Calendar{
+		holidays: map[string]bool{},
+		workdays: map[string]bool{},
+	}
+}
+
+// AddHoliday отмечает день как нерабочий.
+func (c *Calendar) AddHoliday(d time.Time) {
+	key := d.Format(dateLayout)
+	c.holidays[key] = true
+	delete(c.workdays, key)
+}
+
+// AddWorkday отмечает выходной день как рабочий (перенос).
+func (c *Calendar) AddWorkday(d time.Time) {
+	key := d.Format(dateLayout)
+	c.workdays[key] = true
+	delete(c.holidays, key)
+}
+
+// IsWorkday проверяет, является ли день рабочим.
+func (c *Calendar) IsWorkday(d time.Time) bool {
+	key := d.Format(dateLayout)
+	if c.workdays[key] {
+		return true
+	}
+	if c.holidays[key] {
+		return false
+	}
+	return d.Weekday() != time.Saturday && d.Weekday() != time.Sunday
+}
+
+// LoadCalendar загружает календарь из файла. Поддерживаются JSON
+// (простой формат или формат производственного календаря xmlcalendar.ru)
+// и iCalendar (.ics), где каждое событие на весь день — праздник.
+func LoadCalendar(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := NewCalendar()
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = c.loadJSON(data)
+	case ".ics":
+		err = c.loadICS(data)
+	default:
+		err = fmt.Errorf("unsupported calendar format: %s", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// calendarFile описывает оба поддерживаемых JSON-формата:
+//
+//	{"holidays": ["20260101"], "workdays": ["20260103"]}
+//	{"year": 2026, "months": [{"month": 1, "days": "1,2,3,4+,5,6,7,8,10,11,17*"}]}
+//
+// Во втором формате перечислены все нерабочие дни года; "*" отмечает
+// сокращённый рабочий день, "+" — перенесённый выходной.
+type calendarFile struct {
+	Holidays []string `json:"holidays"`
+	Workdays []string `json:"workdays"`
+	Year     int      `json:"year"`
+	Months   []struct {
+		Month int    `json:"month"`
+		Days  string `json:"days"`
+	} `json:"months"`
+}
+
+func (c *Calendar) loadJSON(data []byte) error {
+	var file calendarFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("invalid calendar file: %v", err)
+	}
+
+	for _, s := range file.Holidays {
+		d, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return fmt.Errorf("invalid holiday date: %s", s)
+		}
+		c.AddHoliday(d)
+	}
+	for _, s := range file.Workdays {
+		d, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return fmt.Errorf("invalid workday date: %s", s)
+		}
+		c.AddWorkday(d)
+	}
+
+	if file.Year == 0 {
+		return nil
+	}
+
+	nonWorking := map[string]bool{}
+	for _, month := range file.Months {
+		if month.Month < 1 || month.Month > 12 {
+			return fmt.Errorf("invalid calendar month: %d", month.Month)
+		}
+		for _, s := range strings.Split(month.Days, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" || strings.HasSuffix(s, "*") {
+				continue
+			}
+			day, err := strconv.Atoi(strings.TrimSuffix(s, "+"))
+			d := time.Date(file.Year, time.Month(month.Month), day, 0, 0, 0, 0, time.UTC)
+			// time.Date превратил бы 31 февраля в 2 или 3 марта.
+			if err != nil || day < 1 || d.Month() != time.Month(month.Month) {
+				return fmt.Errorf("invalid calendar day: %d.%s", month.Month, s)
+			}
+			nonWorking[d.Format(dateLayout)] = true
+		}
+	}
+
+	// Календарь на год полный: невыписанные выходные — перенесённые рабочие дни.
+	d := time.Date(file.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for d.Year() == file.Year {
+		weekend := d.Weekday() == time.Saturday || d.Weekday() == time.Sunday
+		switch {
+		case nonWorking[d.Format(dateLayout)] && !weekend:
+			c.AddHoliday(d)
+		case !nonWorking[d.Format(dateLayout)] && weekend:
+			c.AddWorkday(d)
+		}
+		d = d.AddDate(0, 0, 1)
+	}
+
+	return nil
+}
+
+func (c *Calendar) loadICS(data []byte) error {
+	var start, end time.Time
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch {
+		case line == "BEGIN:VEVENT":
+			start, end = time.Time{}, time.Time{}
+		case strings.HasPrefix(name, "DTSTART"):
+			start, err = parseICSDate(value)
+		case strings.HasPrefix(name, "DTEND"):
+			end, err = parseICSDate(value)
+		case line == "END:VEVENT":
+			if start.IsZero() {
+				continue
+			}
+			// DTEND у событий на весь день не включается в событие.
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+				c.AddHoliday(d)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid calendar line: %s", line)
+		}
+	}
+
+	return scanner.Err()
+}
+
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < len(dateLayout) {
+		return time.Time{}, fmt.Errorf("invalid date: %s", value)
+	}
+	return time.Parse(dateLayout, value[:len(dateLayout)])
+}
+
+// addWorkdays прибавляет к дате n рабочих дней.
+func (c *Calendar) addWorkdays(d time.Time, n int) time.Time {
+	for i := 0; n > 0 && i < maxSearchDays; i++ {
+		d = d.AddDate(0, 0, 1)
+		if c.IsWorkday(d) {
+			n--
+		}
+	}
+	return d
+}
+
+// shift переносит нерабочий день на ближайший рабочий в направлении step (+1 или -1).
+func (c *Calendar) shift(d time.Time, step int) time.Time {
+	for i := 0; i < maxSearchDays && !c.IsWorkday(d); i++ {
+		d = d.AddDate(0, 0, step)
+	}
+	return d
+}
@@ -0,0 +1,100 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CatchUp определяет, что делать с повторениями, пропущенными, пока
+// задача оставалась просроченной.
+type CatchUp string
+
+const (
+	// CatchUpOldest показывает задачу на дате самого старого пропущенного
+	// повторения, а выполнение переносит её на первую дату после now.
+	CatchUpOldest CatchUp = "oldest"
+	// CatchUpSkip пропускает просроченные повторения: задача показывается
+	// на ближайшей дате начиная с сегодняшнего дня.
+	CatchUpSkip CatchUp = "skip"
+	// CatchUpEach показывает каждое пропущенное повторение отдельно,
+	// и выполнение закрывает их по одному.
+	CatchUpEach CatchUp = "each"
+)
+
+// maxMissed ограничивает перебор пропущенных повторений.
+const maxMissed = 1000
+
+// ParseCatchUp проверяет политику пропущенных повторений.
+// Пустая строка означает CatchUpOldest.
+func ParseCatchUp(policy string) (CatchUp, error) {
+	switch CatchUp(policy) {
+	case "", CatchUpOldest:
+		return CatchUpOldest, nil
+	case CatchUpSkip, CatchUpEach:
+		return CatchUp(policy), nil
+	default:
+		return "", fmt.Errorf("unsupported catch-up policy: %s", policy)
+	}
+}
+
+// MissedDates возвращает даты повторений начиная с date и не позже
+// сегодняшнего дня — все невыполненные повторения просроченной задачи.
+// Возвращается не больше limit дат.
+func MissedDates(now time.Time, date string, repeat string, limit int) ([]string, error) {
+	dates, _, err := missed(now, date, repeat, limit)
+	return dates, err
+}
+
+// CurrentDate возвращает первое повторение задачи начиная с сегодняшнего
+// дня. Если правило закончилось раньше, возвращается последнее повторение.
+func CurrentDate(now time.Time, date string, repeat string) (string, error) {
+	dates, next, err := missed(now, date, repeat, maxMissed)
+	if err != nil {
+		return "", err
+	}
+	if len(dates) > 0 && (next == "" || dates[len(dates)-1] == wallClock(now).Format(dateLayout)) {
+		return dates[len(dates)-1], nil
+	}
+	return next, nil
+}
+
+// missed перебирает повторения от date до сегодняшнего дня включительно
+// и возвращает их вместе с первым повторением после сегодняшнего дня.
+func missed(now time.Time, date string, repeat string, limit int) ([]string, string, error) {
+	start, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid date format: %s", date)
+	}
+	today := wallClock(now).Format(dateLayout)
+	if date > today {
+		return nil, date, nil
+	}
+	if repeat == "" {
+		return []string{date}, "", nil
+	}
+
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return nil, "", err
+	}
+
+	dates := []string{date}
+	for d := start; len(dates) < limit; {
+		d, err = rule.Next(d, d)
+		if errors.Is(err, ErrRuleEnded) {
+			return dates, "", nil
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		rule = rule.Consume()
+
+		date = d.Format(dateLayout)
+		if date > today {
+			return dates, date, nil
+		}
+		dates = append(dates, date)
+	}
+	return dates, "", nil
+}
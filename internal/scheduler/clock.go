@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock — источник текущего времени. Обработчики получают «сейчас» только
+// через Clock, чтобы в тестах и в режиме имитации даты время можно было
+// остановить или сдвинуть.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock возвращает системное время.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// OffsetClock идёт вместе с системными часами, но со сдвигом Offset.
+type OffsetClock struct {
+	Offset time.Duration
+}
+
+func (c OffsetClock) Now() time.Time {
+	return time.Now().Add(c.Offset)
+}
+
+// NewDateClock возвращает часы, на которых сегодня — дата date в формате
+// YYYYMMDD, а время суток совпадает с системным.
+func NewDateClock(date string, loc *time.Location) (OffsetClock, error) {
+	d, err := time.ParseInLocation(dateLayout, date, loc)
+	if err != nil {
+		return OffsetClock{}, err
+	}
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return OffsetClock{Offset: d.Sub(today)}, nil
+}
+
+// FixedClock стоит на месте, пока его не переведут методами Set и Advance.
+type FixedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewFixedClock(now time.Time) *FixedClock {
+	return &FixedClock{now: now}
+}
+
+func (c *FixedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set переводит часы на момент now.
+func (c *FixedClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance переводит часы вперёд на d.
+func (c *FixedClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
@@ -0,0 +1,60 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const isoDateLayout = "2006-01-02"
+
+// ParseDate разбирает дату из API и возвращает её в формате YYYYMMDD.
+// Кроме YYYYMMDD поддерживаются ISO 8601 (2026-10-18), смещения
+// относительно сегодняшнего дня (+3d, +1w, +2m, +1y, -1d) и слова
+// "today", "tomorrow", "завтра" и т.п. (см. ParseNaturalDate).
+func ParseDate(s string, now time.Time) (string, error) {
+	s = strings.TrimSpace(s)
+
+	if d, err := time.Parse(dateLayout, s); err == nil {
+		return d.Format(dateLayout), nil
+	}
+	if d, err := time.Parse(isoDateLayout, s); err == nil {
+		return d.Format(dateLayout), nil
+	}
+	if d, ok := parseOffset(s, wallClock(now)); ok {
+		return d.Format(dateLayout), nil
+	}
+	if date, err := ParseNaturalDate(s, now); err == nil {
+		return date, nil
+	}
+
+	return "", fmt.Errorf("invalid date: %s", s)
+}
+
+// parseOffset разбирает смещение вида "+3d" или "-1w".
+func parseOffset(s string, today time.Time) (time.Time, bool) {
+	if len(s) < 3 || (s[0] != '+' && s[0] != '-') || s[1] < '0' || s[1] > '9' {
+		return time.Time{}, false
+	}
+
+	n, err := strconv.Atoi(s[1 : len(s)-1])
+	if err != nil || n < 0 || n > 10000 {
+		return time.Time{}, false
+	}
+	if s[0] == '-' {
+		n = -n
+	}
+
+	switch s[len(s)-1] {
+	case 'd':
+		return today.AddDate(0, 0, n), true
+	case 'w':
+		return today.AddDate(0, 0, 7*n), true
+	case 'm':
+		return today.AddDate(0, n, 0), true
+	case 'y':
+		return today.AddDate(n, 0, 0), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,308 @@
+package scheduler
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnrecognized = errors.New("unrecognized phrase")
+
+// Phrase — дата и правило повторения, полученные из фразы на
+// естественном языке.
+type Phrase struct {
+	Date   string `json:"date,omitempty"`
+	Repeat string `json:"repeat,omitempty"`
+}
+
+// ParsePhrase разбирает фразу вроде "завтра", "next friday",
+// "каждый понедельник" или "every 2 weeks". Для повторяющихся задач
+// датой становится первое повторение начиная с сегодняшнего дня.
+func ParsePhrase(text string, now time.Time) (Phrase, error) {
+	if repeat, err := ParseNaturalRepeat(text, now); err == nil {
+		date, err := firstOccurrence(now, repeat)
+		if err != nil {
+			return Phrase{}, err
+		}
+		return Phrase{Date: date, Repeat: repeat}, nil
+	}
+
+	date, err := ParseNaturalDate(text, now)
+	if err != nil {
+		return Phrase{}, err
+	}
+	return Phrase{Date: date}, nil
+}
+
+// ParseNaturalDate разбирает относительную дату: "сегодня", "завтра",
+// "послезавтра", "через 3 дня", "in 2 weeks", "в пятницу", "next friday".
+func ParseNaturalDate(text string, now time.Time) (string, error) {
+	tokens := phraseTokens(text)
+	today := wallClock(now)
+
+	switch strings.Join(tokens, " ") {
+	case "сегодня", "today":
+		return today.Format(dateLayout), nil
+	case "завтра", "tomorrow":
+		return today.AddDate(0, 0, 1).Format(dateLayout), nil
+	case "послезавтра", "day after tomorrow":
+		return today.AddDate(0, 0, 2).Format(dateLayout), nil
+	}
+
+	if len(tokens) >= 2 && (tokens[0] == "через" || tokens[0] == "in") {
+		n, unit := 1, tokens[1:]
+		if len(unit) == 2 {
+			var ok bool
+			if n, ok = parseNumber(unit[0]); !ok {
+				return "", ErrUnrecognized
+			}
+			unit = unit[1:]
+		}
+		if len(unit) != 1 {
+			return "", ErrUnrecognized
+		}
+		switch phraseUnit(unit[0]) {
+		case "day":
+			return today.AddDate(0, 0, n).Format(dateLayout), nil
+		case "week":
+			return today.AddDate(0, 0, 7*n).Format(dateLayout), nil
+		case "month":
+			return today.AddDate(0, n, 0).Format(dateLayout), nil
+		case "year":
+			return today.AddDate(n, 0, 0).Format(dateLayout), nil
+		}
+		return "", ErrUnrecognized
+	}
+
+	// "пятница", "в пятницу", "в следующую пятницу", "next friday", "on friday".
+	for len(tokens) > 1 && isWeekdayPrefix(tokens[0]) {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 1 {
+		if weekday := phraseWeekday(tokens[0]); weekday != 0 {
+			d := today.AddDate(0, 0, 1)
+			for isoWeekday(d) != weekday {
+				d = d.AddDate(0, 0, 1)
+			}
+			return d.Format(dateLayout), nil
+		}
+	}
+
+	return "", ErrUnrecognized
+}
+
+// ParseNaturalRepeat переводит фразу вроде "каждый день", "каждые 3 дня",
+// "по понедельникам и пятницам", "every 2 weeks" или "monthly" в правило
+// повторения. Правила без явного дня ("каждую неделю", "каждый месяц")
+// привязываются к дню now.
+func ParseNaturalRepeat(text string, now time.Time) (string, error) {
+	tokens := phraseTokens(text)
+	today := wallClock(now)
+
+	if len(tokens) == 1 {
+		switch tokens[0] {
+		case "ежедневно", "daily":
+			return "d 1", nil
+		case "еженедельно", "weekly":
+			return "w " + strconv.Itoa(isoWeekday(today)), nil
+		case "ежемесячно", "monthly":
+			return "m " + strconv.Itoa(today.Day()), nil
+		case "ежегодно", "yearly", "annually":
+			return "y", nil
+		}
+	}
+
+	if len(tokens) < 2 {
+		return "", ErrUnrecognized
+	}
+	switch tokens[0] {
+	case "каждый", "каждую", "каждое", "каждые", "every", "по", "on":
+	default:
+		return "", ErrUnrecognized
+	}
+	rest := tokens[1:]
+
+	switch strings.Join(rest, " ") {
+	case "будний день", "будням", "будние дни", "weekday", "weekdays":
+		return "w 1,2,3,4,5", nil
+	case "выходной", "выходным", "выходные", "weekend", "weekends":
+		return "w 6,7", nil
+	case "рабочий день", "рабочим дням", "business day", "working day":
+		return "bd 1", nil
+	}
+
+	if weekdays := phraseWeekdays(rest); len(weekdays) > 0 {
+		return "w " + joinList(weekdays), nil
+	}
+
+	n := 1
+	if len(rest) == 2 {
+		var ok bool
+		if rest[0] == "other" || rest[0] == "second" {
+			n, ok = 2, true
+		} else {
+			n, ok = parseNumber(rest[0])
+		}
+		if !ok {
+			return "", ErrUnrecognized
+		}
+		rest = rest[1:]
+	}
+	if len(rest) != 1 {
+		return "", ErrUnrecognized
+	}
+
+	var repeat string
+	switch phraseUnit(rest[0]) {
+	case "day":
+		repeat = "d " + strconv.Itoa(n)
+	case "week":
+		if n == 1 {
+			repeat = "w " + strconv.Itoa(isoWeekday(today))
+		} else {
+			repeat = "d " + strconv.Itoa(7*n)
+		}
+	case "month":
+		if n == 1 {
+			repeat = "m " + strconv.Itoa(today.Day())
+		} else {
+			repeat = "FREQ=MONTHLY;INTERVAL=" + strconv.Itoa(n)
+		}
+	case "year":
+		if n == 1 {
+			repeat = "y"
+		} else {
+			repeat = "FREQ=YEARLY;INTERVAL=" + strconv.Itoa(n)
+		}
+	default:
+		return "", ErrUnrecognized
+	}
+
+	if _, err := ParseRule(repeat); err != nil {
+		return "", err
+	}
+	return repeat, nil
+}
+
+// firstOccurrence возвращает первую дату повторения начиная с сегодняшнего дня.
+func firstOccurrence(now time.Time, repeat string) (string, error) {
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return "", err
+	}
+
+	today := wallClock(now)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	switch rule.Type {
+	case Daily, Yearly, RRule:
+		// Эти правила отсчитываются от даты задачи, поэтому начинаются сегодня.
+		return today.Format(dateLayout), nil
+	}
+
+	yesterday := today.AddDate(0, 0, -1)
+	date, err := rule.Next(yesterday, yesterday)
+	if err != nil {
+		return "", err
+	}
+	return date.Format(dateLayout), nil
+}
+
+func phraseTokens(text string) []string {
+	text = strings.ToLower(strings.ReplaceAll(text, "ё", "е"))
+	text = strings.NewReplacer(",", " ", ".", " ", ";", " ", "!", " ").Replace(text)
+
+	var tokens []string
+	for _, token := range strings.Fields(text) {
+		if token != "и" && token != "and" && token != "a" && token != "an" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+func phraseUnit(token string) string {
+	switch token {
+	case "день", "дня", "дней", "day", "days":
+		return "day"
+	case "неделя", "неделю", "недели", "недель", "week", "weeks":
+		return "week"
+	case "месяц", "месяца", "месяцев", "month", "months":
+		return "month"
+	case "год", "года", "лет", "year", "years":
+		return "year"
+	}
+	return ""
+}
+
+var phraseNumbers = map[string]int{
+	"один": 1, "одну": 1, "one": 1,
+	"два": 2, "две": 2, "two": 2,
+	"три": 3, "three": 3,
+	"четыре": 4, "four": 4,
+	"пять": 5, "five": 5,
+	"шесть": 6, "six": 6,
+	"семь": 7, "seven": 7,
+	"десять": 10, "ten": 10,
+}
+
+func parseNumber(token string) (int, bool) {
+	if n, err := strconv.Atoi(token); err == nil {
+		return n, n > 0
+	}
+	n, ok := phraseNumbers[token]
+	return n, ok
+}
+
+// Русские дни недели распознаются по основе слова, чтобы подходила
+// любая падежная форма, английские — только целиком.
+var phraseWeekdayPrefixes = []struct {
+	prefix  string
+	weekday int
+}{
+	{"понедельн", 1}, {"вторник", 2}, {"сред", 3}, {"четверг", 4},
+	{"пятниц", 5}, {"суббот", 6}, {"воскресен", 7},
+}
+
+var phraseWeekdayNames = []string{"", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// phraseWeekday возвращает номер дня недели по ISO 8601 или 0.
+func phraseWeekday(token string) int {
+	for _, p := range phraseWeekdayPrefixes {
+		if strings.HasPrefix(token, p.prefix) {
+			return p.weekday
+		}
+	}
+	for weekday, name := range phraseWeekdayNames {
+		if weekday > 0 && (token == name || token == name+"s" || token == name[:3]) {
+			return weekday
+		}
+	}
+	return 0
+}
+
+// phraseWeekdays возвращает дни недели, если все слова — дни недели.
+func phraseWeekdays(tokens []string) []int {
+	var weekdays []int
+	for _, token := range tokens {
+		weekday := phraseWeekday(token)
+		if weekday == 0 {
+			return nil
+		}
+		if !contains(weekdays, weekday) {
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	sort.Ints(weekdays)
+	return weekdays
+}
+
+func isWeekdayPrefix(token string) bool {
+	switch token {
+	case "в", "во", "на", "on", "next", "this",
+		"следующий", "следующую", "следующее", "ближайший", "ближайшую", "ближайшее":
+		return true
+	}
+	return false
+}
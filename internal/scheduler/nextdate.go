@@ -1,15 +1,24 @@
 package scheduler
 
 import (
+	"errors"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 )
 
 const dateLayout = "20060102"
 
+// maxSearchDays ограничивает перебор дат для правил, которые могут
+// никогда не выполниться (например, "m 31 2").
+const maxSearchDays = 3660
+
 func NextDate(now time.Time, date string, repeat string) (string, error) {
+	return NextDateExcept(now, date, repeat, nil)
+}
+
+// NextDateExcept работает как NextDate, но пропускает даты из except.
+func NextDateExcept(now time.Time, date string, repeat string, except []string) (string, error) {
+	now = wallClock(now)
 	targetDate, err := time.Parse(dateLayout, date)
 	if err != nil {
 		return "", fmt.Errorf("invalid date format: %s", date)
@@ -19,24 +28,190 @@ func NextDate(now time.Time, date string, repeat string) (string, error) {
 		return "", nil
 	}
 
-	repeatParts := strings.Fields(repeat)
-	if len(repeatParts) == 0 {
-		return "", fmt.Errorf("invalid repeat format")
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return "", err
+	}
+	rule.Except = exceptSet(except)
+
+	nextDate, err := rule.Next(now, targetDate)
+	if err != nil {
+		return "", err
+	}
+
+	return nextDate.Format(dateLayout), nil
+}
+
+// RepeatMode определяет, от какой даты отсчитывается следующее повторение
+// при выполнении задачи.
+type RepeatMode string
+
+const (
+	// ModeFixed сохраняет календарный график: отсчёт идёт от даты задачи.
+	ModeFixed RepeatMode = "fixed"
+	// ModeCompletion отсчитывает следующее повторение от дня выполнения.
+	ModeCompletion RepeatMode = "completion"
+)
+
+// ParseRepeatMode проверяет режим повторения. Пустая строка означает ModeFixed.
+func ParseRepeatMode(mode string) (RepeatMode, error) {
+	switch RepeatMode(mode) {
+	case "", ModeFixed:
+		return ModeFixed, nil
+	case ModeCompletion:
+		return ModeCompletion, nil
+	default:
+		return "", fmt.Errorf("unsupported repeat mode: %s", mode)
+	}
+}
+
+// NextDateMode вычисляет дату следующего повторения задачи, выполненной
+// в момент now, с учётом режима повторения и исключённых дат.
+func NextDateMode(now time.Time, date string, repeat string, mode RepeatMode, except []string) (string, error) {
+	if mode == ModeCompletion {
+		if _, err := time.Parse(dateLayout, date); err != nil {
+			return "", fmt.Errorf("invalid date format: %s", date)
+		}
+		date = now.Format(dateLayout)
+	}
+
+	return NextDateExcept(now, date, repeat, except)
+}
+
+func exceptSet(dates []string) map[string]bool {
+	if len(dates) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(dates))
+	for _, d := range dates {
+		set[d] = true
+	}
+	return set
+}
+
+// NextDates возвращает до count следующих дат повторения. Каждая дата
+// вычисляется от предыдущей так же, как при выполнении задачи в этот день.
+// Если правило заканчивается раньше, дат возвращается меньше.
+func NextDates(now time.Time, date string, repeat string, count int) ([]string, error) {
+	now = wallClock(now)
+	targetDate, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %s", date)
+	}
+
+	if repeat == "" {
+		return []string{}, nil
+	}
+
+	rule, err := ParseRule(repeat)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make([]string, 0, count)
+	for len(dates) < count {
+		nextDate, err := rule.Next(now, targetDate)
+		if errors.Is(err, ErrRuleEnded) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		dates = append(dates, nextDate.Format(dateLayout))
+		now, targetDate = nextDate, nextDate
+		rule = rule.Consume()
+	}
+
+	return dates, nil
+}
+
+// Next возвращает следующую после date дату повторения с учётом
+// текущего момента now, пропуская даты из r.Except. Если повторений
+// больше не осталось, возвращается ErrRuleEnded.
+func (r Rule) Next(now, date time.Time) (time.Time, error) {
+	if r.Count == 1 {
+		return time.Time{}, ErrRuleEnded
+	}
+
+	nextDate, err := r.nextShifted(now, date)
+	for i := 0; err == nil && r.Except[nextDate.Format(dateLayout)]; i++ {
+		if i == maxSearchDays {
+			return time.Time{}, ErrNoOccurrence
+		}
+		nextDate, err = r.nextShifted(nextDate, nextDate)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !r.Until.IsZero() && nextDate.Format(dateLayout) > r.Until.Format(dateLayout) {
+		return time.Time{}, ErrRuleEnded
+	}
+
+	return nextDate, nil
+}
+
+// nextShifted применяет к повторению перенос с нерабочего дня. Если после
+// переноса дата не позже уже пройденной, берётся следующее повторение.
+func (r Rule) nextShifted(now, date time.Time) (time.Time, error) {
+	if r.Shift == ShiftNone {
+		return r.next(now, date)
+	}
+
+	step := 1
+	if r.Shift == ShiftPrev {
+		step = -1
+	}
+
+	last := dayNumber(date)
+	if dayNumber(now) > last {
+		last = dayNumber(now)
+	}
+
+	base, baseNow := date, now
+	for i := 0; i < maxSearchDays; i++ {
+		nextDate, err := r.next(baseNow, base)
+		if err != nil {
+			return time.Time{}, err
+		}
+		shifted := calendar.shift(nextDate, step)
+		if dayNumber(shifted) > last {
+			return shifted, nil
+		}
+		base, baseNow = nextDate, nextDate
 	}
 
-	repeatType := repeatParts[0]
+	return time.Time{}, ErrNoOccurrence
+}
 
-	switch repeatType {
-	case "y": 
-		return handleYearlyRepeat(now, targetDate)
-	case "d": 
-		return handleDailyRepeat(now, targetDate, repeatParts)
+func (r Rule) next(now, date time.Time) (time.Time, error) {
+	switch r.Type {
+	case Yearly:
+		return nextYearly(now, date), nil
+	case Daily:
+		return nextDaily(now, date, r.Interval), nil
+	case BusinessDaily:
+		return nextBusinessDay(now, date, r.Interval), nil
+	case Weekly:
+		return searchNext(now, date, func(d time.Time) bool {
+			return contains(r.Weekdays, isoWeekday(d))
+		})
+	case Monthly:
+		return searchNext(now, date, r.matchesMonthly)
+	case MonthlyWeekday:
+		return searchNext(now, date, r.matchesMonthlyWeekday)
+	case RRule:
+		from := date
+		if now.After(from) {
+			from = now
+		}
+		return r.RRule.next(date, from)
 	default:
-		return "", fmt.Errorf("unsupported repeat type: %s", repeatType)
+		return time.Time{}, &RuleError{Rule: r.String(), Value: string(r.Type), Err: ErrUnsupportedType}
 	}
 }
 
-func handleYearlyRepeat(now, date time.Time) (string, error) {
+func nextYearly(now, date time.Time) time.Time {
 	if date.Before(now) {
 		for date.Before(now) {
 			date = date.AddDate(1, 0, 0)
@@ -45,19 +220,10 @@ func handleYearlyRepeat(now, date time.Time) (string, error) {
 		date = date.AddDate(1, 0, 0)
 	}
 
-	return date.Format(dateLayout), nil
+	return date
 }
 
-func handleDailyRepeat(now, date time.Time, repeatParts []string) (string, error) {
-	if len(repeatParts) < 2 {
-		return "", fmt.Errorf("invalid daily repeat format")
-	}
-
-	days, err := strconv.Atoi(repeatParts[1])
-	if err != nil || days <= 0 || days > 400 {
-		return "", fmt.Errorf("invalid number of days: %s", repeatParts[1])
-	}
-
+func nextDaily(now, date time.Time, days int) time.Time {
 	nextDate := date
 	if nextDate.Before(now) || nextDate.Equal(now) {
 		for nextDate.Before(now) || nextDate.Equal(now) {
@@ -67,5 +233,75 @@ func handleDailyRepeat(now, date time.Time, repeatParts []string) (string, error
 		nextDate = nextDate.AddDate(0, 0, days)
 	}
 
-	return nextDate.Format(dateLayout), nil
+	return nextDate
+}
+
+// nextBusinessDay отсчитывает от date по days рабочих дней, пока
+// результат не окажется позже now.
+func nextBusinessDay(now, date time.Time, days int) time.Time {
+	nextDate := calendar.addWorkdays(date, days)
+	for dayNumber(nextDate) <= dayNumber(now) {
+		nextDate = calendar.addWorkdays(nextDate, days)
+	}
+
+	return nextDate
+}
+
+func (r Rule) matchesMonthly(d time.Time) bool {
+	if len(r.Months) > 0 && !contains(r.Months, int(d.Month())) {
+		return false
+	}
+	return matchesMonthDay(r.Days, d)
+}
+
+// matchesMonthlyWeekday проверяет, что d — N-й (или N-й с конца) из
+// выбранных дней недели в месяце.
+func (r Rule) matchesMonthlyWeekday(d time.Time) bool {
+	if len(r.Months) > 0 && !contains(r.Months, int(d.Month())) {
+		return false
+	}
+	if !contains(r.Weekdays, isoWeekday(d)) {
+		return false
+	}
+	fromStart := (d.Day()-1)/7 + 1
+	fromEnd := -((daysInMonth(d)-d.Day())/7 + 1)
+	return contains(r.Ordinals, fromStart) || contains(r.Ordinals, fromEnd)
+}
+
+// searchNext перебирает дни после max(now, date) и возвращает первый,
+// для которого выполняется условие matches.
+func searchNext(now, date time.Time, matches func(time.Time) bool) (time.Time, error) {
+	nextDate := date
+	if now.After(nextDate) {
+		nextDate = now
+	}
+
+	for i := 0; i < maxSearchDays; i++ {
+		nextDate = nextDate.AddDate(0, 0, 1)
+		if matches(nextDate) {
+			return nextDate, nil
+		}
+	}
+
+	return time.Time{}, ErrNoOccurrence
+}
+
+// wallClock переносит показания часов now в UTC, чтобы сравнивать их
+// с датами задач, которые разбираются как полночь UTC. Иначе «сегодня»
+// в часовом поясе запроса могло бы оказаться вчерашним днём по UTC.
+func wallClock(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day(),
+		now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), time.UTC)
+}
+
+func daysInMonth(d time.Time) int {
+	return time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, d.Location()).Day()
+}
+
+// isoWeekday возвращает номер дня недели по ISO 8601: 1 — понедельник, 7 — воскресенье.
+func isoWeekday(d time.Time) int {
+	if d.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(d.Weekday())
 }
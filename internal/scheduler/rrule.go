@@ -0,0 +1,337 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency — значение FREQ правила RRULE.
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// maxRRuleInterval ограничивает INTERVAL, чтобы перебор дат оставался
+// конечным даже для правил, которые никогда не выполняются.
+const maxRRuleInterval = 400
+
+var weekdayCodes = []string{"", "MO", "TU", "WE", "TH", "FR", "SA", "SU"}
+
+// WeekdayNum — элемент BYDAY: день недели по ISO 8601 и необязательный
+// порядковый номер (2TU — второй вторник, -1FR — последняя пятница).
+type WeekdayNum struct {
+	Ordinal int
+	Weekday int
+}
+
+func (wn WeekdayNum) String() string {
+	if wn.Ordinal != 0 {
+		return strconv.Itoa(wn.Ordinal) + weekdayCodes[wn.Weekday]
+	}
+	return weekdayCodes[wn.Weekday]
+}
+
+// Recurrence — поддерживаемое подмножество RRULE из RFC 5545.
+// COUNT и UNTIL хранятся в полях Count и Until правила Rule.
+type Recurrence struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	WeekStart  int
+}
+
+// isRRule проверяет, что строка повторения записана в формате RRULE.
+func isRRule(repeat string) bool {
+	upper := strings.ToUpper(strings.TrimSpace(repeat))
+	return strings.HasPrefix(upper, "RRULE:") || strings.HasPrefix(upper, "FREQ=")
+}
+
+// parseRRule разбирает правило вида "FREQ=WEEKLY;BYDAY=MO,WE;INTERVAL=2".
+// Префикс "RRULE:" допускается, но не обязателен.
+func parseRRule(repeat string) (Rule, error) {
+	value := strings.ToUpper(strings.TrimSpace(repeat))
+	value = strings.TrimPrefix(value, "RRULE:")
+
+	rule := Rule{Type: RRule}
+	rec := &Recurrence{Interval: 1, WeekStart: 1}
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" || seen[key] {
+			return Rule{}, &RuleError{Rule: repeat, Field: "RRULE", Value: part, Err: ErrInvalidFormat}
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			rec.Freq = Frequency(val)
+			switch rec.Freq {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+			default:
+				return Rule{}, &RuleError{Rule: repeat, Field: "FREQ", Value: val, Err: ErrUnsupportedType}
+			}
+		case "INTERVAL":
+			rec.Interval, err = strconv.Atoi(val)
+			if err != nil || rec.Interval <= 0 || rec.Interval > maxRRuleInterval {
+				return Rule{}, &RuleError{Rule: repeat, Field: "INTERVAL", Value: val, Err: ErrInvalidValue}
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err != nil || rule.Count <= 0 {
+				return Rule{}, &RuleError{Rule: repeat, Field: "COUNT", Value: val, Err: ErrInvalidValue}
+			}
+		case "UNTIL":
+			// Время в UNTIL (20261231T235959Z) не учитывается: задачи хранятся с точностью до дня.
+			if len(val) < len(dateLayout) {
+				return Rule{}, &RuleError{Rule: repeat, Field: "UNTIL", Value: val, Err: ErrInvalidValue}
+			}
+			rule.Until, err = time.Parse(dateLayout, val[:len(dateLayout)])
+			if err != nil {
+				return Rule{}, &RuleError{Rule: repeat, Field: "UNTIL", Value: val, Err: ErrInvalidValue}
+			}
+		case "BYDAY":
+			rec.ByDay, err = parseByDay(val)
+			if err != nil {
+				return Rule{}, &RuleError{Rule: repeat, Field: "BYDAY", Value: val, Err: ErrInvalidValue}
+			}
+		case "BYMONTHDAY":
+			rec.ByMonthDay, err = parseList(val, -31, 31)
+			if err != nil || contains(rec.ByMonthDay, 0) {
+				return Rule{}, &RuleError{Rule: repeat, Field: "BYMONTHDAY", Value: val, Err: ErrInvalidValue}
+			}
+		case "BYMONTH":
+			rec.ByMonth, err = parseList(val, 1, 12)
+			if err != nil {
+				return Rule{}, &RuleError{Rule: repeat, Field: "BYMONTH", Value: val, Err: ErrInvalidValue}
+			}
+		case "WKST":
+			rec.WeekStart = weekdayIndex(val)
+			if rec.WeekStart == 0 {
+				return Rule{}, &RuleError{Rule: repeat, Field: "WKST", Value: val, Err: ErrInvalidValue}
+			}
+		default:
+			return Rule{}, &RuleError{Rule: repeat, Field: "RRULE", Value: key, Err: ErrUnsupportedType}
+		}
+	}
+
+	if rec.Freq == "" {
+		return Rule{}, &RuleError{Rule: repeat, Field: "FREQ", Err: ErrInvalidFormat}
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return Rule{}, &RuleError{Rule: repeat, Field: "RRULE", Value: "COUNT and UNTIL", Err: ErrInvalidFormat}
+	}
+	for _, wd := range rec.ByDay {
+		if wd.Ordinal != 0 && rec.Freq != FreqMonthly && rec.Freq != FreqYearly {
+			return Rule{}, &RuleError{Rule: repeat, Field: "BYDAY", Value: wd.String(), Err: ErrInvalidValue}
+		}
+	}
+	// Как и для "m 30 2": 30 февраля не наступит ни при каком INTERVAL.
+	if len(rec.ByMonth) > 0 && len(rec.ByMonthDay) > 0 && !monthDaysPossible(rec.ByMonth, rec.ByMonthDay) {
+		return Rule{}, &RuleError{Rule: repeat, Field: "RRULE", Value: rec.String(), Err: ErrNoOccurrence}
+	}
+
+	rule.RRule = rec
+	return rule, nil
+}
+
+func parseByDay(s string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, part := range strings.Split(s, ",") {
+		if len(part) < 2 {
+			return nil, ErrInvalidValue
+		}
+		wd := WeekdayNum{Weekday: weekdayIndex(part[len(part)-2:])}
+		if wd.Weekday == 0 {
+			return nil, ErrInvalidValue
+		}
+		if ord := part[:len(part)-2]; ord != "" {
+			n, err := strconv.Atoi(ord)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, ErrInvalidValue
+			}
+			wd.Ordinal = n
+		}
+		days = append(days, wd)
+	}
+	return days, nil
+}
+
+func weekdayIndex(code string) int {
+	for i, c := range weekdayCodes {
+		if i > 0 && c == code {
+			return i
+		}
+	}
+	return 0
+}
+
+// String возвращает правило в формате RRULE без префикса "RRULE:".
+func (rec Recurrence) String() string {
+	parts := []string{"FREQ=" + string(rec.Freq)}
+	if rec.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(rec.Interval))
+	}
+	if len(rec.ByDay) > 0 {
+		days := make([]string, len(rec.ByDay))
+		for i, wd := range rec.ByDay {
+			days[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(rec.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinList(rec.ByMonthDay))
+	}
+	if len(rec.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinList(rec.ByMonth))
+	}
+	if rec.WeekStart != 1 {
+		parts = append(parts, "WKST="+weekdayCodes[rec.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// matches проверяет, что d — повторение правила, начатого в start (DTSTART).
+func (rec Recurrence) matches(start, d time.Time) bool {
+	if !rec.inPeriod(start, d) {
+		return false
+	}
+	if len(rec.ByMonth) > 0 && !contains(rec.ByMonth, int(d.Month())) {
+		return false
+	}
+	if len(rec.ByMonthDay) > 0 && !matchesMonthDay(rec.ByMonthDay, d) {
+		return false
+	}
+	if len(rec.ByDay) > 0 && !rec.matchesByDay(d) {
+		return false
+	}
+
+	// Без BY-правил день повторения берётся из даты начала.
+	switch rec.Freq {
+	case FreqWeekly:
+		if len(rec.ByDay) == 0 {
+			return d.Weekday() == start.Weekday()
+		}
+	case FreqMonthly:
+		if len(rec.ByDay) == 0 && len(rec.ByMonthDay) == 0 {
+			return d.Day() == start.Day()
+		}
+	case FreqYearly:
+		if len(rec.ByDay) == 0 && len(rec.ByMonthDay) == 0 {
+			if len(rec.ByMonth) == 0 && d.Month() != start.Month() {
+				return false
+			}
+			return d.Day() == start.Day()
+		}
+	}
+	return true
+}
+
+// next возвращает первое после from повторение правила, начатого в start.
+// Периоды, не кратные INTERVAL, пропускаются целиком, поэтому перебор
+// ограничен maxSearchDays проверенных дней независимо от INTERVAL.
+func (rec Recurrence) next(start, from time.Time) (time.Time, error) {
+	d := from
+	for i := 0; i < maxSearchDays; i++ {
+		d = d.AddDate(0, 0, 1)
+		if !rec.inPeriod(start, d) {
+			d = rec.nextPeriod(start, d)
+		}
+		if rec.matches(start, d) {
+			return d, nil
+		}
+	}
+	return time.Time{}, ErrNoOccurrence
+}
+
+// nextPeriod возвращает первый день ближайшего после d периода с номером,
+// кратным INTERVAL.
+func (rec Recurrence) nextPeriod(start, d time.Time) time.Time {
+	n := rec.period(start, d)
+	n += rec.Interval - (n%rec.Interval+rec.Interval)%rec.Interval
+
+	switch rec.Freq {
+	case FreqDaily:
+		return start.AddDate(0, 0, n)
+	case FreqWeekly:
+		return rec.weekStartOf(start).AddDate(0, 0, 7*n)
+	case FreqMonthly:
+		return time.Date(start.Year(), start.Month()+time.Month(n), 1, 0, 0, 0, 0, start.Location())
+	default:
+		return time.Date(start.Year()+n, time.January, 1, 0, 0, 0, 0, start.Location())
+	}
+}
+
+// inPeriod проверяет, что d попадает в период с номером, кратным INTERVAL.
+func (rec Recurrence) inPeriod(start, d time.Time) bool {
+	return rec.period(start, d)%rec.Interval == 0
+}
+
+// period возвращает номер периода FREQ, в который попадает d, считая
+// от периода start.
+func (rec Recurrence) period(start, d time.Time) int {
+	switch rec.Freq {
+	case FreqDaily:
+		return dayNumber(d) - dayNumber(start)
+	case FreqWeekly:
+		return (dayNumber(rec.weekStartOf(d)) - dayNumber(rec.weekStartOf(start))) / 7
+	case FreqMonthly:
+		return (d.Year()-start.Year())*12 + int(d.Month()) - int(start.Month())
+	default:
+		return d.Year() - start.Year()
+	}
+}
+
+func (rec Recurrence) weekStartOf(d time.Time) time.Time {
+	return d.AddDate(0, 0, -((isoWeekday(d) - rec.WeekStart + 7) % 7))
+}
+
+func (rec Recurrence) matchesByDay(d time.Time) bool {
+	weekday := isoWeekday(d)
+	for _, wd := range rec.ByDay {
+		if wd.Weekday != weekday {
+			continue
+		}
+		if wd.Ordinal == 0 {
+			return true
+		}
+
+		// Для YEARLY без BYMONTH номер считается в пределах года, иначе — месяца.
+		day, total := d.Day(), daysInMonth(d)
+		if rec.Freq == FreqYearly && len(rec.ByMonth) == 0 {
+			day, total = d.YearDay(), daysInYear(d)
+		}
+		if wd.Ordinal == (day-1)/7+1 || wd.Ordinal == -((total-day)/7+1) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(days []int, d time.Time) bool {
+	lastDay := daysInMonth(d)
+	for _, day := range days {
+		if day == d.Day() || (day < 0 && lastDay+day+1 == d.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// dayNumber возвращает порядковый номер календарного дня, не зависящий
+// от времени суток и часового пояса.
+func dayNumber(d time.Time) int {
+	return int(time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+func daysInYear(d time.Time) int {
+	return time.Date(d.Year(), time.December, 31, 0, 0, 0, 0, d.Location()).YearDay()
+}
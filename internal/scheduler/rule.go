@@ -0,0 +1,359 @@
+package scheduler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuleType определяет вид правила повторения.
+type RuleType string
+
+const (
+	Daily   RuleType = "d"
+	Weekly  RuleType = "w"
+	Monthly RuleType = "m"
+	Yearly  RuleType = "y"
+
+	MonthlyWeekday RuleType = "mw"
+	BusinessDaily  RuleType = "bd"
+	RRule          RuleType = "RRULE"
+)
+
+// Shift задаёт перенос повторения, выпавшего на нерабочий день.
+type Shift string
+
+const (
+	ShiftNone Shift = ""
+	ShiftNext Shift = "next"
+	ShiftPrev Shift = "prev"
+)
+
+var (
+	ErrEmptyRule       = errors.New("empty repeat rule")
+	ErrUnsupportedType = errors.New("unsupported repeat type")
+	ErrInvalidFormat   = errors.New("invalid repeat format")
+	ErrInvalidValue    = errors.New("invalid repeat value")
+	ErrNoOccurrence    = errors.New("no matching date found")
+	ErrRuleEnded       = errors.New("repeat rule has ended")
+)
+
+// RuleError описывает ошибку разбора правила повторения.
+// Err всегда содержит одну из ошибок ErrXxx пакета, поэтому её можно
+// проверять через errors.Is.
+type RuleError struct {
+	Rule  string
+	Field string
+	Value string
+	Err   error
+}
+
+func (e *RuleError) Error() string {
+	msg := e.Err.Error()
+	if e.Field != "" {
+		msg += " for " + e.Field
+	}
+	if e.Value != "" {
+		msg += ": " + e.Value
+	}
+	return msg
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Rule — разобранное правило повторения задачи.
+type Rule struct {
+	Type     RuleType
+	Interval int   // количество дней для Daily и рабочих дней для BusinessDaily
+	Weekdays []int // дни недели по ISO 8601 для Weekly и MonthlyWeekday
+	Days     []int // дни месяца для Monthly, -1 и -2 — последний и предпоследний
+	Months   []int // месяцы для Monthly и MonthlyWeekday, пустой список — любой месяц
+	Ordinals []int // номера дней недели в месяце для MonthlyWeekday, -1 — последний
+
+	RRule *Recurrence // правило RFC 5545 для RRule
+
+	// Условия окончания. Until — последняя допустимая дата (нулевое
+	// значение — без ограничения), Count — сколько повторений осталось,
+	// включая текущее (0 — без ограничения).
+	Until time.Time
+	Count int
+
+	// Shift переносит повторение с нерабочего дня на ближайший рабочий.
+	Shift Shift
+
+	// Except — даты в формате YYYYMMDD, исключённые из повторений
+	// (пропущенные или перенесённые).
+	Except map[string]bool
+}
+
+// ParseRule разбирает строку повторения вида "d 7", "y", "w 1,4,5",
+// "m 1,15", "m -1 1,6", "mw 2 2" (второй вторник месяца) или "bd 1"
+// (каждый рабочий день). В конце правила можно указать условия окончания
+// "until YYYYMMDD" и "count N", а также перенос с нерабочих дней
+// "shift next" или "shift prev".
+// Также поддерживается RRULE: "FREQ=WEEKLY;BYDAY=MO,WE;INTERVAL=2".
+func ParseRule(repeat string) (Rule, error) {
+	if isRRule(repeat) {
+		return parseRRule(repeat)
+	}
+
+	parts := strings.Fields(repeat)
+	if len(parts) == 0 {
+		return Rule{}, &RuleError{Rule: repeat, Err: ErrEmptyRule}
+	}
+
+	rule := Rule{Type: RuleType(parts[0])}
+	args := parts[1:]
+
+	for i, arg := range args {
+		if arg == "until" || arg == "count" || arg == "shift" {
+			if err := rule.parseModifiers(repeat, args[i:]); err != nil {
+				return Rule{}, err
+			}
+			args = args[:i]
+			break
+		}
+	}
+
+	var err error
+	switch rule.Type {
+	case Yearly:
+		if len(args) != 0 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "yearly rule", Err: ErrInvalidFormat}
+		}
+	case Daily:
+		if len(args) != 1 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "daily rule", Err: ErrInvalidFormat}
+		}
+		rule.Interval, err = strconv.Atoi(args[0])
+		if err != nil || rule.Interval <= 0 || rule.Interval > 400 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "number of days", Value: args[0], Err: ErrInvalidValue}
+		}
+	case BusinessDaily:
+		if len(args) != 1 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "business day rule", Err: ErrInvalidFormat}
+		}
+		rule.Interval, err = strconv.Atoi(args[0])
+		if err != nil || rule.Interval <= 0 || rule.Interval > 250 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "number of business days", Value: args[0], Err: ErrInvalidValue}
+		}
+	case Weekly:
+		if len(args) != 1 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "weekly rule", Err: ErrInvalidFormat}
+		}
+		rule.Weekdays, err = parseList(args[0], 1, 7)
+		if err != nil {
+			return Rule{}, &RuleError{Rule: repeat, Field: "days of week", Value: args[0], Err: ErrInvalidValue}
+		}
+	case Monthly:
+		if len(args) < 1 || len(args) > 2 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "monthly rule", Err: ErrInvalidFormat}
+		}
+		rule.Days, err = parseList(args[0], -2, 31)
+		if err != nil || contains(rule.Days, 0) {
+			return Rule{}, &RuleError{Rule: repeat, Field: "days of month", Value: args[0], Err: ErrInvalidValue}
+		}
+		if len(args) == 2 {
+			rule.Months, err = parseList(args[1], 1, 12)
+			if err != nil {
+				return Rule{}, &RuleError{Rule: repeat, Field: "months", Value: args[1], Err: ErrInvalidValue}
+			}
+			if !rule.monthlyPossible() {
+				return Rule{}, &RuleError{Rule: repeat, Field: "monthly rule", Value: repeat, Err: ErrNoOccurrence}
+			}
+		}
+	case MonthlyWeekday:
+		if len(args) < 2 || len(args) > 3 {
+			return Rule{}, &RuleError{Rule: repeat, Field: "monthly weekday rule", Err: ErrInvalidFormat}
+		}
+		rule.Ordinals, err = parseList(args[0], -5, 5)
+		if err != nil || contains(rule.Ordinals, 0) {
+			return Rule{}, &RuleError{Rule: repeat, Field: "weekday numbers", Value: args[0], Err: ErrInvalidValue}
+		}
+		rule.Weekdays, err = parseList(args[1], 1, 7)
+		if err != nil {
+			return Rule{}, &RuleError{Rule: repeat, Field: "days of week", Value: args[1], Err: ErrInvalidValue}
+		}
+		if len(args) == 3 {
+			rule.Months, err = parseList(args[2], 1, 12)
+			if err != nil {
+				return Rule{}, &RuleError{Rule: repeat, Field: "months", Value: args[2], Err: ErrInvalidValue}
+			}
+			if !rule.monthlyWeekdayPossible() {
+				return Rule{}, &RuleError{Rule: repeat, Field: "monthly weekday rule", Value: repeat, Err: ErrNoOccurrence}
+			}
+		}
+	default:
+		return Rule{}, &RuleError{Rule: repeat, Value: parts[0], Err: ErrUnsupportedType}
+	}
+
+	return rule, nil
+}
+
+// parseModifiers разбирает пары "until YYYYMMDD", "count N" и
+// "shift next|prev" в конце правила.
+func (r *Rule) parseModifiers(repeat string, args []string) error {
+	if len(args)%2 != 0 {
+		return &RuleError{Rule: repeat, Field: "rule modifier", Err: ErrInvalidFormat}
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < len(args); i += 2 {
+		key, value := args[i], args[i+1]
+		if seen[key] {
+			return &RuleError{Rule: repeat, Field: "rule modifier", Value: key, Err: ErrInvalidFormat}
+		}
+		seen[key] = true
+
+		switch key {
+		case "until":
+			until, err := time.Parse(dateLayout, value)
+			if err != nil {
+				return &RuleError{Rule: repeat, Field: "until", Value: value, Err: ErrInvalidValue}
+			}
+			r.Until = until
+		case "count":
+			count, err := strconv.Atoi(value)
+			if err != nil || count <= 0 {
+				return &RuleError{Rule: repeat, Field: "count", Value: value, Err: ErrInvalidValue}
+			}
+			r.Count = count
+		case "shift":
+			r.Shift = Shift(value)
+			if r.Shift != ShiftNext && r.Shift != ShiftPrev {
+				return &RuleError{Rule: repeat, Field: "shift", Value: value, Err: ErrInvalidValue}
+			}
+		default:
+			return &RuleError{Rule: repeat, Field: "rule modifier", Value: key, Err: ErrInvalidFormat}
+		}
+	}
+
+	return nil
+}
+
+// String возвращает каноническую запись правила.
+func (r Rule) String() string {
+	var s string
+	switch r.Type {
+	case Daily:
+		s = "d " + strconv.Itoa(r.Interval)
+	case BusinessDaily:
+		s = "bd " + strconv.Itoa(r.Interval)
+	case Weekly:
+		s = "w " + joinList(r.Weekdays)
+	case Monthly:
+		s = "m " + joinList(r.Days)
+		if len(r.Months) > 0 {
+			s += " " + joinList(r.Months)
+		}
+	case MonthlyWeekday:
+		s = "mw " + joinList(r.Ordinals) + " " + joinList(r.Weekdays)
+		if len(r.Months) > 0 {
+			s += " " + joinList(r.Months)
+		}
+	case RRule:
+		s = r.RRule.String()
+		if !r.Until.IsZero() {
+			s += ";UNTIL=" + r.Until.Format(dateLayout)
+		}
+		if r.Count > 0 {
+			s += ";COUNT=" + strconv.Itoa(r.Count)
+		}
+		return s
+	default:
+		s = string(r.Type)
+	}
+
+	if !r.Until.IsZero() {
+		s += " until " + r.Until.Format(dateLayout)
+	}
+	if r.Count > 0 {
+		s += " count " + strconv.Itoa(r.Count)
+	}
+	if r.Shift != ShiftNone {
+		s += " shift " + string(r.Shift)
+	}
+	return s
+}
+
+// Consume возвращает правило после выполнения одного повторения:
+// для правил с "count" счётчик уменьшается на единицу.
+func (r Rule) Consume() Rule {
+	if r.Count > 0 {
+		r.Count--
+	}
+	return r
+}
+
+// monthlyPossible проверяет, что хотя бы один из дней встречается
+// хотя бы в одном из выбранных месяцев.
+func (r Rule) monthlyPossible() bool {
+	return monthDaysPossible(r.Months, r.Days)
+}
+
+// monthlyWeekdayPossible проверяет, что N-й день недели встречается хотя
+// бы в одном из выбранных месяцев. Пятый день недели в феврале бывает только
+// в високосный год, когда на него приходится 29-е (или 1-е) число, то есть
+// раз в 28 лет, и поиск следующей даты до него не доходит.
+func (r Rule) monthlyWeekdayPossible() bool {
+	if len(r.Months) == 0 {
+		return true
+	}
+	for _, month := range r.Months {
+		if month != 2 {
+			return true
+		}
+	}
+	for _, ordinal := range r.Ordinals {
+		if ordinal > -5 && ordinal < 5 {
+			return true
+		}
+	}
+	return false
+}
+
+// monthDaysPossible проверяет, что хотя бы один из дней месяца (отрицательные
+// считаются с конца) встречается хотя бы в одном из месяцев.
+func monthDaysPossible(months, days []int) bool {
+	maxDays := [13]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+	for _, month := range months {
+		for _, day := range days {
+			if day <= maxDays[month] && -day <= maxDays[month] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseList(s string, min, max int) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(s, ",") {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < min || value > max {
+			return nil, ErrInvalidValue
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func joinList(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func contains(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"html"
+	"log"
+	"strings"
+)
+
+// ErrFullTextUnavailable возвращается, если полнотекстовый индекс не создан:
+// SQLite собран без FTS5 (нужен тег сборки sqlite_fts5).
+var ErrFullTextUnavailable = errors.New("full-text search is not available")
+
+// FullTextSearcher реализуют хранилища с полнотекстовым индексом.
+type FullTextSearcher interface {
+	// FullTextSearch возвращает до limit задач, подходящих под запрос,
+	// от самых релевантных. Кроме полей задачи в результате есть
+	// "highlight" — название с выделенными совпадениями — и "snippet" —
+	// фрагмент комментария вокруг совпадения.
+	FullTextSearch(query string, limit int) ([]map[string]string, error)
+}
+
+var _ FullTextSearcher = (*Storage)(nil)
+
+// Маркеры, которыми выделяются совпадения в highlight и snippet. Остальной
+// текст в этих полях экранирован для HTML.
+const (
+	HighlightStart = "<b>"
+	HighlightEnd   = "</b>"
+)
+
+// matchStart и matchEnd отмечают совпадения, пока текст не экранирован.
+const (
+	matchStart = "\x02"
+	matchEnd   = "\x03"
+)
+
+// highlightHTML экранирует текст для HTML и заменяет маркеры совпадений
+// тегами выделения.
+func highlightHTML(text string) string {
+	return strings.NewReplacer(matchStart, HighlightStart, matchEnd, HighlightEnd).Replace(html.EscapeString(text))
+}
+
+// setupFullText создаёт индекс FTS5 по названию и комментарию задач и
+// триггеры, которые поддерживают его в актуальном состоянии. Индекс не
+// входит в миграции: он производный и доступен не во всех сборках.
+// Возвращает false, если SQLite собран без FTS5.
+func setupFullText(db *sql.DB) (bool, error) {
+	// CREATE ... IF NOT EXISTS не проверяет модуль, если таблица уже есть,
+	// поэтому поддержка FTS5 проверяется по опциям сборки.
+	var available bool
+	if err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&available); err != nil {
+		return false, err
+	}
+	if !available {
+		// Триггеры, созданные сборкой с FTS5, ломают запись в scheduler.
+		// Без них индекс устаревает и будет перестроен при следующем запуске с FTS5.
+		_, err := db.Exec(`
+		DROP TRIGGER IF EXISTS tasks_fts_insert;
+		DROP TRIGGER IF EXISTS tasks_fts_delete;
+		DROP TRIGGER IF EXISTS tasks_fts_update;`)
+		return false, err
+	}
+
+	createIndexQuery := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS tasks_fts USING fts5(
+		title, comment,
+		content='scheduler', content_rowid='id',
+		tokenize='unicode61 remove_diacritics 2'
+	);`
+	if _, err := db.Exec(createIndexQuery); err != nil {
+		return false, err
+	}
+
+	var triggers int
+	err := db.QueryRow(`SELECT count(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'tasks_fts_%'`).Scan(&triggers)
+	if err != nil {
+		return false, err
+	}
+	if triggers == 3 {
+		return true, nil
+	}
+
+	log.Println("Building full-text search index...")
+	createTriggersQuery := `
+	CREATE TRIGGER IF NOT EXISTS tasks_fts_insert AFTER INSERT ON scheduler BEGIN
+		INSERT INTO tasks_fts(rowid, title, comment) VALUES (new.id, new.title, new.comment);
+	END;
+	CREATE TRIGGER IF NOT EXISTS tasks_fts_delete AFTER DELETE ON scheduler BEGIN
+		INSERT INTO tasks_fts(tasks_fts, rowid, title, comment) VALUES ('delete', old.id, old.title, old.comment);
+	END;
+	CREATE TRIGGER IF NOT EXISTS tasks_fts_update AFTER UPDATE OF title, comment ON scheduler BEGIN
+		INSERT INTO tasks_fts(tasks_fts, rowid, title, comment) VALUES ('delete', old.id, old.title, old.comment);
+		INSERT INTO tasks_fts(rowid, title, comment) VALUES (new.id, new.title, new.comment);
+	END;
+	INSERT INTO tasks_fts(tasks_fts) VALUES ('rebuild');`
+	if _, err := db.Exec(createTriggersQuery); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *Storage) FullTextSearch(query string, limit int) ([]map[string]string, error) {
+	if !s.fullText {
+		return nil, ErrFullTextUnavailable
+	}
+
+	match := ftsQuery(query)
+	if match == "" {
+		return []map[string]string{}, nil
+	}
+
+	ftsSelect := `SELECT s.id, s.date, s.due_time, s.title, COALESCE(s.comment, ''), s.repeat, s.repeat_mode, s.catchup,
+		s.project_id, s.assignee_id,
+		highlight(tasks_fts, 0, ?, ?), COALESCE(snippet(tasks_fts, 1, ?, ?, '…', 12), '')
+	FROM tasks_fts JOIN scheduler s ON s.id = tasks_fts.rowid
+	WHERE tasks_fts MATCH ? AND ` + visibleTasks + `
+	ORDER BY rank LIMIT ?`
+	rows, err := s.DB.Query(ftsSelect, matchStart, matchEnd, matchStart, matchEnd, match, s.owner, s.owner, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []map[string]string{}
+	for rows.Next() {
+		var task Task
+		var highlight, snippet string
+		if err := rows.Scan(append(task.fields(), &highlight, &snippet)...); err != nil {
+			return nil, err
+		}
+		result := task.toMap()
+		result["highlight"] = highlightHTML(highlight)
+		result["snippet"] = highlightHTML(snippet)
+		tasks = append(tasks, result)
+	}
+
+	return tasks, rows.Err()
+}
+
+// ftsQuery переводит строку поиска в безопасный запрос FTS5: каждое слово
+// и каждая фраза в двойных кавычках берутся в кавычки целиком, "*" в конце
+// слова означает поиск по префиксу. Все части должны встретиться в задаче.
+func ftsQuery(query string) string {
+	var parts []string
+	for _, term := range SearchTerms(query) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.TrimSuffix(term, "*")
+		if term == "" {
+			continue
+		}
+		part := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			part += "*"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchTerms разбивает строку поиска на слова и фразы в двойных кавычках.
+// У слов сохраняется "*" в конце.
+func SearchTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			if phrase := strings.Join(strings.Fields(part), " "); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
@@ -0,0 +1,435 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryStorage хранит задачи в памяти процесса. Подходит для тестов и
+// демонстрационных запусков: данные пропадают при остановке сервера.
+type MemoryStorage struct {
+	*memoryData
+
+	owner int64
+}
+
+// memoryData — общие данные хранилища и всех его копий из ForOwner.
+type memoryData struct {
+	mu         sync.Mutex
+	lastID     int64
+	tasks      map[int64]Task
+	owners     map[int64]int64
+	exceptions map[int64][]Exception
+	// completions хранятся в порядке добавления.
+	completions []ownedCompletion
+
+	lastUserID int64
+	users      map[string]User
+
+	lastProjectID int64
+	projects      map[int64]Project
+	members       map[int64]map[int64]Role
+}
+
+type ownedCompletion struct {
+	Completion
+	owner     int64
+	projectID int64
+}
+
+var _ TaskRepository = (*MemoryStorage)(nil)
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{memoryData: &memoryData{
+		tasks:      map[int64]Task{},
+		owners:     map[int64]int64{},
+		exceptions: map[int64][]Exception{},
+		users:      map[string]User{},
+		projects:   map[int64]Project{},
+		members:    map[int64]map[int64]Role{},
+	}}
+}
+
+// access сообщает, видит ли владелец хранилища задачу пользователя owner
+// из проекта projectID и может ли изменять её. Вызывается под s.mu.
+func (s *MemoryStorage) access(owner, projectID int64) (visible, editable bool) {
+	if projectID == 0 {
+		return owner == s.owner, owner == s.owner
+	}
+	role, ok := s.members[projectID][s.owner]
+	return ok, role.CanEdit()
+}
+
+// visible сообщает, видит ли владелец хранилища задачу id.
+// Вызывается под s.mu.
+func (s *MemoryStorage) visible(id int64) bool {
+	task, ok := s.tasks[id]
+	if !ok {
+		return false
+	}
+	visible, _ := s.access(s.owners[id], task.ProjectID)
+	return visible
+}
+
+// checkEditable вызывается под s.mu.
+func (s *MemoryStorage) checkEditable(id int64) error {
+	task, ok := s.tasks[id]
+	if !ok {
+		return ErrTaskNotFound
+	}
+	visible, editable := s.access(s.owners[id], task.ProjectID)
+	if !visible {
+		return ErrTaskNotFound
+	}
+	if !editable {
+		return ErrTaskReadOnly
+	}
+	return nil
+}
+
+func (s *MemoryStorage) AddTask(task *Task) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := checkPlacement(task, s.owner, s.role); err != nil {
+		return 0, err
+	}
+
+	s.lastID++
+	stored := *task
+	stored.ID = s.lastID
+	s.tasks[stored.ID] = stored
+	s.owners[stored.ID] = s.owner
+	return stored.ID, nil
+}
+
+func (s *MemoryStorage) GetTaskByID(id int64) (*Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.visible(id) {
+		return nil, ErrTaskNotFound
+	}
+	task := s.tasks[id]
+	return &task, nil
+}
+
+func (s *MemoryStorage) GetUpcomingTasks(filter TaskFilter, limit int) ([]map[string]string, error) {
+	return s.filterTasks(filter, limit, func(Task) bool { return true })
+}
+
+// filterTasks возвращает до limit подходящих задач, упорядоченных по дате,
+// времени и id.
+func (s *MemoryStorage) filterTasks(filter TaskFilter, limit int, match func(Task) bool) ([]map[string]string, error) {
+	s.mu.Lock()
+	tasks := make([]Task, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		if (filter.ProjectID != 0 && task.ProjectID != filter.ProjectID) ||
+			(filter.AssigneeID != 0 && task.AssigneeID != filter.AssigneeID) {
+			continue
+		}
+		if s.visible(task.ID) && match(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Date != tasks[j].Date {
+			return tasks[i].Date < tasks[j].Date
+		}
+		if tasks[i].Time != tasks[j].Time {
+			return tasks[i].Time < tasks[j].Time
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	if len(tasks) > limit {
+		tasks = tasks[:limit]
+	}
+
+	result := make([]map[string]string, len(tasks))
+	for i, task := range tasks {
+		result[i] = task.toMap()
+	}
+	return result, nil
+}
+
+func (s *MemoryStorage) SearchTasks(text string, filter TaskFilter, limit int) ([]map[string]string, error) {
+	text = strings.ToLower(text)
+	return s.filterTasks(filter, limit, func(task Task) bool {
+		return strings.Contains(strings.ToLower(task.Title), text) ||
+			strings.Contains(strings.ToLower(task.Comment), text)
+	})
+}
+
+func (s *MemoryStorage) GetTasksByDate(date string, filter TaskFilter, limit int) ([]map[string]string, error) {
+	return s.filterTasks(filter, limit, func(task Task) bool {
+		return task.Date == date
+	})
+}
+
+func (s *MemoryStorage) UpdateTask(task *Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch err := s.checkEditable(task.ID); err {
+	case nil:
+		if err := checkPlacement(task, s.owner, s.role); err != nil {
+			return err
+		}
+		s.tasks[task.ID] = *task
+		if task.ProjectID == 0 {
+			s.owners[task.ID] = s.owner
+		}
+	case ErrTaskReadOnly:
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStorage) UpdateTaskDate(id int64, date, repeat string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch err := s.checkEditable(id); err {
+	case nil:
+		task := s.tasks[id]
+		task.Date = date
+		task.Repeat = repeat
+		s.tasks[id] = task
+	case ErrTaskReadOnly:
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStorage) DeleteTask(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkEditable(id); err != nil {
+		return err
+	}
+	delete(s.tasks, id)
+	delete(s.owners, id)
+	delete(s.exceptions, id)
+	return nil
+}
+
+func (s *MemoryStorage) TaskExists(id int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.visible(id), nil
+}
+
+func (s *MemoryStorage) AddException(taskID int64, date, movedTo string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch err := s.checkEditable(taskID); err {
+	case nil:
+		s.exceptions[taskID] = append(s.exceptions[taskID], Exception{Date: date, MovedTo: movedTo})
+	case ErrTaskReadOnly:
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStorage) ResolveMove(taskID int64, date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch err := s.checkEditable(taskID); err {
+	case nil:
+		for i, e := range s.exceptions[taskID] {
+			if e.Date == date {
+				s.exceptions[taskID][i].MovedTo = ""
+			}
+		}
+	case ErrTaskReadOnly:
+		return err
+	}
+	return nil
+}
+
+func (s *MemoryStorage) GetExceptions(taskID int64) ([]Exception, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.visible(taskID) {
+		return nil, nil
+	}
+	exceptions := append([]Exception(nil), s.exceptions[taskID]...)
+	sort.SliceStable(exceptions, func(i, j int) bool {
+		return exceptions[i].Date < exceptions[j].Date
+	})
+	return exceptions, nil
+}
+
+func (s *MemoryStorage) CompleteTask(c Completion, nextDate, repeat string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkEditable(c.TaskID); err != nil {
+		return err
+	}
+	s.completions = append(s.completions, ownedCompletion{
+		Completion: c,
+		owner:      s.owner,
+		projectID:  s.tasks[c.TaskID].ProjectID,
+	})
+	if nextDate == "" {
+		delete(s.tasks, c.TaskID)
+		delete(s.owners, c.TaskID)
+		delete(s.exceptions, c.TaskID)
+		return nil
+	}
+	task := s.tasks[c.TaskID]
+	task.Date = nextDate
+	task.Repeat = repeat
+	s.tasks[c.TaskID] = task
+	return nil
+}
+
+func (s *MemoryStorage) GetCompletions(taskID int64) ([]Completion, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	completions := []Completion{}
+	for i := len(s.completions) - 1; i >= 0; i-- {
+		c := s.completions[i]
+		if visible, _ := s.access(c.owner, c.projectID); c.TaskID == taskID && visible {
+			completions = append(completions, c.Completion)
+		}
+	}
+	sort.SliceStable(completions, func(i, j int) bool {
+		return completions[i].CompletedAt > completions[j].CompletedAt
+	})
+	return completions, nil
+}
+
+func (s *MemoryStorage) AddUser(user *User) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user.Login]; ok {
+		return 0, ErrUserExists
+	}
+	s.lastUserID++
+	stored := *user
+	stored.ID = s.lastUserID
+	s.users[stored.Login] = stored
+	return stored.ID, nil
+}
+
+func (s *MemoryStorage) GetUserByLogin(login string) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[login]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (s *MemoryStorage) AddProject(project *Project, ownerID int64) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastProjectID++
+	stored := *project
+	stored.ID = s.lastProjectID
+	stored.Role = ""
+	s.projects[stored.ID] = stored
+	s.members[stored.ID] = map[int64]Role{ownerID: RoleOwner}
+	return stored.ID, nil
+}
+
+func (s *MemoryStorage) GetProjects(userID int64) ([]Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects := []Project{}
+	for id, members := range s.members {
+		if role, ok := members[userID]; ok {
+			project := s.projects[id]
+			project.Role = role
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID < projects[j].ID
+	})
+	return projects, nil
+}
+
+func (s *MemoryStorage) ProjectRole(projectID, userID int64) (Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.role(projectID, userID)
+}
+
+// role — ProjectRole без блокировки. Вызывается под s.mu.
+func (s *MemoryStorage) role(projectID, userID int64) (Role, error) {
+	role, ok := s.members[projectID][userID]
+	if !ok {
+		return "", ErrProjectNotFound
+	}
+	return role, nil
+}
+
+func (s *MemoryStorage) SetMember(projectID, userID int64, role Role) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if members, ok := s.members[projectID]; ok {
+		members[userID] = role
+	}
+	return nil
+}
+
+func (s *MemoryStorage) RemoveMember(projectID, userID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.members[projectID][userID]; !ok {
+		return ErrUserNotFound
+	}
+	delete(s.members[projectID], userID)
+	return nil
+}
+
+func (s *MemoryStorage) GetMembers(projectID int64) ([]ProjectMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	logins := make(map[int64]string, len(s.users))
+	for _, user := range s.users {
+		logins[user.ID] = user.Login
+	}
+
+	members := []ProjectMember{}
+	for userID, role := range s.members[projectID] {
+		members = append(members, ProjectMember{UserID: userID, Login: logins[userID], Role: role})
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].UserID < members[j].UserID
+	})
+	return members, nil
+}
+
+// ForOwner возвращает хранилище с общими с s данными.
+func (s *MemoryStorage) ForOwner(ownerID int64) TaskRepository {
+	return &MemoryStorage{memoryData: s.memoryData, owner: ownerID}
+}
+
+func (s *MemoryStorage) Close() error {
+	return nil
+}
@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect — вид базы данных, для которой написаны миграции.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// Файлы миграций лежат в migrations/<dialect>/ и называются
+// NNNN_описание.sql; номер задаёт порядок применения.
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration — одна миграция схемы.
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Migrations возвращает все миграции диалекта по возрастанию версии.
+func Migrations(dialect Dialect) ([]Migration, error) {
+	dir := path.Join("migrations", string(dialect))
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported dialect: %s", dialect)
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		name := entry.Name()
+		number, _, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || !strings.HasSuffix(name, ".sql") {
+			return nil, fmt.Errorf("invalid migration file name: %s", name)
+		}
+
+		data, err := migrationFiles.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{Version: version, Name: name, SQL: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("duplicate migration version: %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// PendingMigrations возвращает миграции, которые ещё не применены к базе.
+// База не изменяется.
+func PendingMigrations(db *sql.DB, dialect Dialect) ([]Migration, error) {
+	migrations, err := Migrations(dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := schemaVersion(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range migrations {
+		if m.Version > current {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate применяет к базе недостающие миграции. Каждая миграция
+// выполняется в своей транзакции и записывается в таблицу schema_version.
+func Migrate(db *sql.DB, dialect Dialect) error {
+	migrations, err := Migrations(dialect)
+	if err != nil {
+		return err
+	}
+
+	current, err := schemaVersion(db, dialect)
+	if err != nil {
+		return err
+	}
+
+	createVersionQuery := `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	);`
+	if _, err := db.Exec(createVersionQuery); err != nil {
+		return err
+	}
+
+	insertVersionQuery := `INSERT OR IGNORE INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`
+	if dialect == DialectPostgres {
+		insertVersionQuery = `INSERT INTO schema_version (version, name, applied_at) VALUES ($1, $2, $3)
+		ON CONFLICT (version) DO NOTHING`
+	}
+
+	for _, m := range migrations {
+		appliedAt := time.Now().UTC().Format(time.RFC3339)
+
+		// Схема, созданная до появления миграций, уже содержит эти изменения:
+		// они только отмечаются как применённые.
+		if m.Version <= current {
+			if _, err := db.Exec(insertVersionQuery, m.Version, m.Name, appliedAt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		log.Printf("Applying migration %s...", m.Name)
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(m.SQL); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %v", m.Name, err)
+		}
+		if _, err := tx.Exec(insertVersionQuery, m.Version, m.Name, appliedAt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %s: %v", m.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %s: %v", m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// schemaVersion возвращает номер последней применённой миграции.
+// Для базы без таблицы schema_version версия определяется по её схеме.
+func schemaVersion(db *sql.DB, dialect Dialect) (int, error) {
+	exists, err := tableExists(db, dialect, "schema_version")
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return legacyVersion(db, dialect)
+	}
+
+	var version sql.NullInt64
+	err = db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version)
+	return int(version.Int64), err
+}
+
+// legacyVersion определяет версию схемы, созданной до появления миграций:
+// раньше недостающие колонки добавлялись при каждом запуске.
+func legacyVersion(db *sql.DB, dialect Dialect) (int, error) {
+	exists, err := tableExists(db, dialect, "scheduler")
+	if err != nil || !exists {
+		return 0, err
+	}
+	if dialect == DialectPostgres {
+		// Хранилище PostgreSQL сразу создавало полную схему.
+		return 1, nil
+	}
+
+	version := 1
+	for _, step := range []struct {
+		version int
+		table   string
+		column  string
+	}{
+		{2, "scheduler", "repeat_mode"},
+		{3, "scheduler", "due_time"},
+		{4, "task_exceptions", ""},
+		{5, "scheduler", "catchup"},
+	} {
+		var ok bool
+		if step.column == "" {
+			ok, err = tableExists(db, dialect, step.table)
+		} else {
+			ok, err = hasColumn(db, step.table, step.column)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+		version = step.version
+	}
+	return version, nil
+}
+
+func tableExists(db *sql.DB, dialect Dialect, table string) (bool, error) {
+	var exists bool
+	var err error
+	if dialect == DialectPostgres {
+		err = db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists)
+	} else {
+		err = db.QueryRow(`SELECT EXISTS(SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)`, table).Scan(&exists)
+	}
+	return exists, err
+}
+
+// hasColumn проверяет, есть ли колонка в таблице SQLite.
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
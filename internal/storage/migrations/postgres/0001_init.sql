@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS scheduler (
+	id BIGSERIAL PRIMARY KEY,
+	date TEXT NOT NULL,
+	due_time TEXT NOT NULL DEFAULT '',
+	title TEXT NOT NULL,
+	comment TEXT,
+	repeat TEXT,
+	repeat_mode TEXT NOT NULL DEFAULT 'fixed',
+	catchup TEXT NOT NULL DEFAULT 'oldest'
+);
+CREATE INDEX IF NOT EXISTS idx_date ON scheduler(date);
+
+CREATE TABLE IF NOT EXISTS task_exceptions (
+	id BIGSERIAL PRIMARY KEY,
+	task_id BIGINT NOT NULL,
+	date TEXT NOT NULL,
+	moved_to TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_exceptions_task ON task_exceptions(task_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS task_completions (
+	id BIGSERIAL PRIMARY KEY,
+	task_id BIGINT NOT NULL,
+	date TEXT NOT NULL,
+	completed_at TEXT NOT NULL,
+	note TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_completions_task ON task_completions(task_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS users (
+	id BIGSERIAL PRIMARY KEY,
+	login TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+ALTER TABLE scheduler ADD COLUMN IF NOT EXISTS owner_id BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_owner_date ON scheduler(owner_id, date);
+
+ALTER TABLE task_completions ADD COLUMN IF NOT EXISTS owner_id BIGINT NOT NULL DEFAULT 0;
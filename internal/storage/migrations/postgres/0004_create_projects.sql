@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS projects (
+	id BIGSERIAL PRIMARY KEY,
+	name TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS project_members (
+	project_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	role TEXT NOT NULL,
+	PRIMARY KEY (project_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_members_user ON project_members(user_id);
+
+ALTER TABLE scheduler ADD COLUMN IF NOT EXISTS project_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE scheduler ADD COLUMN IF NOT EXISTS assignee_id BIGINT NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_project_date ON scheduler(project_id, date);
+
+ALTER TABLE task_completions ADD COLUMN IF NOT EXISTS project_id BIGINT NOT NULL DEFAULT 0;
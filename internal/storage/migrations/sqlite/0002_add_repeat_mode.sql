@@ -0,0 +1 @@
+ALTER TABLE scheduler ADD COLUMN repeat_mode TEXT NOT NULL DEFAULT 'fixed';
@@ -0,0 +1 @@
+ALTER TABLE scheduler ADD COLUMN due_time TEXT NOT NULL DEFAULT '';
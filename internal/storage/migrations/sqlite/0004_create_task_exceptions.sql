@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS task_exceptions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	date TEXT NOT NULL,
+	moved_to TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_exceptions_task ON task_exceptions(task_id);
@@ -0,0 +1 @@
+ALTER TABLE scheduler ADD COLUMN catchup TEXT NOT NULL DEFAULT 'oldest';
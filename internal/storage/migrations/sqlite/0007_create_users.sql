@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	login TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+ALTER TABLE scheduler ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_owner_date ON scheduler(owner_id, date);
+
+ALTER TABLE task_completions ADD COLUMN owner_id INTEGER NOT NULL DEFAULT 0;
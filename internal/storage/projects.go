@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrProjectNotFound возвращается, если проекта нет или пользователь
+	// в нём не участвует.
+	ErrProjectNotFound = errors.New("проект не найден")
+	// ErrTaskReadOnly возвращается при изменении задачи проекта, в котором
+	// у пользователя роль viewer.
+	ErrTaskReadOnly = errors.New("недостаточно прав для изменения задачи")
+	// ErrInvalidAssignee возвращается, если исполнитель не участвует
+	// в проекте задачи. Исполнителем личной задачи может быть только её владелец.
+	ErrInvalidAssignee = errors.New("исполнитель не участвует в проекте")
+)
+
+// Role — роль участника проекта.
+type Role string
+
+const (
+	// RoleOwner управляет участниками и изменяет задачи.
+	RoleOwner Role = "owner"
+	// RoleEditor изменяет задачи проекта.
+	RoleEditor Role = "editor"
+	// RoleViewer только просматривает задачи проекта.
+	RoleViewer Role = "viewer"
+)
+
+// ParseRole проверяет название роли.
+func ParseRole(s string) (Role, error) {
+	switch role := Role(s); role {
+	case RoleOwner, RoleEditor, RoleViewer:
+		return role, nil
+	}
+	return "", fmt.Errorf("unknown role %q", s)
+}
+
+// CanEdit сообщает, может ли участник с этой ролью изменять задачи.
+func (r Role) CanEdit() bool {
+	return r == RoleOwner || r == RoleEditor
+}
+
+// Project — общий список задач.
+type Project struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at"`
+	// Role — роль пользователя, запросившего список проектов.
+	Role Role `json:"role,omitempty"`
+}
+
+// ProjectMember — участник проекта.
+type ProjectMember struct {
+	UserID int64  `json:"user_id"`
+	Login  string `json:"login"`
+	Role   Role   `json:"role"`
+}
+
+// TaskFilter ограничивает списки задач. Нулевые поля не ограничивают.
+type TaskFilter struct {
+	ProjectID  int64
+	AssigneeID int64
+}
+
+// ProjectRepository — хранилище проектов и участников. Его реализуют все
+// хранилища задач.
+type ProjectRepository interface {
+	// AddProject создаёт проект, владельцем которого становится ownerID.
+	AddProject(project *Project, ownerID int64) (int64, error)
+	// GetProjects возвращает проекты, в которых участвует userID, с его ролью.
+	GetProjects(userID int64) ([]Project, error)
+	// ProjectRole возвращает роль userID в проекте или ErrProjectNotFound.
+	ProjectRole(projectID, userID int64) (Role, error)
+	// SetMember добавляет участника или меняет его роль.
+	SetMember(projectID, userID int64, role Role) error
+	// RemoveMember исключает участника. Если его нет, возвращается ErrUserNotFound.
+	RemoveMember(projectID, userID int64) error
+	GetMembers(projectID int64) ([]ProjectMember, error)
+}
+
+var (
+	_ ProjectRepository = (*Storage)(nil)
+	_ ProjectRepository = (*PostgresStorage)(nil)
+	_ ProjectRepository = (*MemoryStorage)(nil)
+)
+
+// checkPlacement проверяет, что ownerID может поместить задачу в её проект
+// и назначить её исполнителя. role возвращает роль пользователя в проекте.
+func checkPlacement(task *Task, ownerID int64, role func(projectID, userID int64) (Role, error)) error {
+	if task.ProjectID == 0 {
+		if task.AssigneeID != 0 && task.AssigneeID != ownerID {
+			return ErrInvalidAssignee
+		}
+		return nil
+	}
+
+	ownerRole, err := role(task.ProjectID, ownerID)
+	if err != nil {
+		return err
+	}
+	if !ownerRole.CanEdit() {
+		return ErrTaskReadOnly
+	}
+
+	if task.AssigneeID != 0 {
+		_, err := role(task.ProjectID, task.AssigneeID)
+		if errors.Is(err, ErrProjectNotFound) {
+			return ErrInvalidAssignee
+		}
+		return err
+	}
+	return nil
+}
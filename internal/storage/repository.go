@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrTaskNotFound возвращается, если задачи с указанным id нет.
+var ErrTaskNotFound = errors.New("задача не найдена")
+
+// TaskRepository — хранилище задач, с которым работают обработчики.
+// Storage реализует его поверх SQLite, PostgresStorage — поверх PostgreSQL,
+// MemoryStorage хранит задачи в памяти.
+type TaskRepository interface {
+	// AddTask сохраняет новую задачу от имени владельца хранилища и
+	// возвращает её id. Для задачи проекта нужна роль owner или editor, а
+	// исполнитель должен участвовать в проекте; иначе возвращается
+	// ErrProjectNotFound, ErrTaskReadOnly или ErrInvalidAssignee.
+	AddTask(task *Task) (int64, error)
+	GetTaskByID(id int64) (*Task, error)
+	// GetUpcomingTasks возвращает до limit задач, упорядоченных по дате и времени.
+	GetUpcomingTasks(filter TaskFilter, limit int) ([]map[string]string, error)
+	// SearchTasks возвращает до limit задач, в названии или комментарии
+	// которых встречается text без учёта регистра.
+	SearchTasks(text string, filter TaskFilter, limit int) ([]map[string]string, error)
+	// GetTasksByDate возвращает до limit задач на дату date (YYYYMMDD).
+	GetTasksByDate(date string, filter TaskFilter, limit int) ([]map[string]string, error)
+	// Методы, изменяющие задачу, возвращают ErrTaskReadOnly, если у
+	// пользователя роль viewer в её проекте, и ErrTaskNotFound, если он не
+	// видит задачу. UpdateTask, UpdateTaskDate, AddException и ResolveMove,
+	// как и UPDATE в SQL, не считают отсутствие задачи ошибкой.
+	//
+	// UpdateTask переносит задачу в другой проект по тем же правилам, что и
+	// AddTask. Задача, перенесённая из проекта в личные, достаётся
+	// переносящему.
+	UpdateTask(task *Task) error
+	// UpdateTaskDate переносит задачу на date и сохраняет правило повторения
+	// repeat, у которого после выполнения мог измениться счётчик.
+	UpdateTaskDate(id int64, date, repeat string) error
+	// DeleteTask удаляет задачу вместе с её исключениями.
+	DeleteTask(id int64) error
+	TaskExists(id int64) (bool, error)
+
+	AddException(taskID int64, date, movedTo string) error
+	GetExceptions(taskID int64) ([]Exception, error)
+	// ResolveMove отмечает перенесённое повторение date выполненным или
+	// пропущенным: date остаётся исключённой, а дата, на которую его
+	// перенесли, больше не считается этим повторением.
+	ResolveMove(taskID int64, date string) error
+
+	// CompleteTask записывает выполнение c и атомарно с ним переносит задачу
+	// на nextDate с правилом repeat, а если nextDate пустая — удаляет её.
+	// Выполнение помнит проект задачи: история задачи проекта доступна его
+	// участникам и после удаления задачи.
+	CompleteTask(c Completion, nextDate, repeat string) error
+	// GetCompletions возвращает выполнения задачи от новых к старым.
+	GetCompletions(taskID int64) ([]Completion, error)
+
+	// ForOwner возвращает хранилище, которое видит только личные задачи
+	// пользователя ownerID и задачи его проектов и создаёт задачи от его
+	// имени. Само хранилище работает с задачами без владельца (owner_id = 0).
+	ForOwner(ownerID int64) TaskRepository
+
+	Close() error
+}
+
+var _ TaskRepository = (*Storage)(nil)
+
+// likePattern возвращает шаблон LIKE для поиска подстроки text без учёта
+// регистра. Символы %, _ и \ экранируются обратной косой чертой.
+func likePattern(text string) string {
+	text = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(text))
+	return "%" + text + "%"
+}
@@ -2,67 +2,161 @@ package storage
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
-	"os"
 	"strconv"
+	"strings"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
+// sqliteDriver — драйвер SQLite, в котором функция lower заменена на
+// strings.ToLower, чтобы поиск без учёта регистра работал и для кириллицы.
+const sqliteDriver = "sqlite3_todo"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("lower", strings.ToLower, true)
+		},
+	})
+}
+
 type Storage struct {
 	DB *sql.DB
+
+	// fullText — создан ли индекс FTS5 для FullTextSearch.
+	fullText bool
+	// owner — пользователь, задачами которого ограничено хранилище.
+	owner int64
 }
 
 type Task struct {
-	ID      int64  `json:"id"`
-	Date    string `json:"date"`
-	Title   string `json:"title"`
-	Comment string `json:"comment"`
-	Repeat  string `json:"repeat"`
+	ID         int64  `json:"id"`
+	Date       string `json:"date"`
+	Time       string `json:"time"`
+	Title      string `json:"title"`
+	Comment    string `json:"comment"`
+	Repeat     string `json:"repeat"`
+	RepeatMode string `json:"repeat_mode"`
+	CatchUp    string `json:"catchup"`
+	// ProjectID — проект задачи, 0 у личных задач.
+	ProjectID int64 `json:"project_id"`
+	// AssigneeID — исполнитель, 0 если не назначен.
+	AssigneeID int64 `json:"assignee_id"`
 }
 
-func NewStorage(dbPath string) (*Storage, error) {
-	_, err := os.Stat(dbPath)
-	install := os.IsNotExist(err)
+// taskColumns — колонки scheduler в порядке Task.fields.
+const taskColumns = `id, date, due_time, title, COALESCE(comment, ''), COALESCE(repeat, ''), repeat_mode, catchup, project_id, assignee_id`
 
-	db, err := sql.Open("sqlite3", dbPath)
+// fields возвращает указатели на поля задачи для Scan.
+func (task *Task) fields() []any {
+	return []any{&task.ID, &task.Date, &task.Time, &task.Title, &task.Comment, &task.Repeat, &task.RepeatMode, &task.CatchUp, &task.ProjectID, &task.AssigneeID}
+}
+
+// toMap возвращает задачу в виде, в котором её отдаёт /api/tasks.
+func (task Task) toMap() map[string]string {
+	return map[string]string{
+		"id":          strconv.FormatInt(task.ID, 10), // Преобразуем ID в строку
+		"date":        task.Date,
+		"time":        task.Time,
+		"title":       task.Title,
+		"comment":     task.Comment,
+		"repeat":      task.Repeat,
+		"repeat_mode": task.RepeatMode,
+		"catchup":     task.CatchUp,
+		"project_id":  strconv.FormatInt(task.ProjectID, 10),
+		"assignee_id": strconv.FormatInt(task.AssigneeID, 10),
+	}
+}
+
+// NewStorage открывает файл базы SQLite, создавая его при необходимости,
+// и применяет недостающие миграции схемы.
+func NewStorage(dbPath string) (*Storage, error) {
+	db, err := OpenDatabase(DialectSQLite, dbPath)
 	if err != nil {
 		return nil, err
 	}
 
-	if install {
-		log.Println("Creating new database and table...")
+	if err := Migrate(db, DialectSQLite); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-		createTableQuery := `
-		CREATE TABLE IF NOT EXISTS scheduler (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			date TEXT NOT NULL,
-			title TEXT NOT NULL,
-			comment TEXT,
-			repeat TEXT
-		);`
-		_, err = db.Exec(createTableQuery)
-		if err != nil {
-			return nil, err
-		}
+	fullText, err := setupFullText(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if !fullText {
+		log.Println("SQLite is built without FTS5, full-text search falls back to substring matching")
+	}
 
-		createIndexQuery := `
-		CREATE INDEX idx_date ON scheduler(date);`
-		_, err = db.Exec(createIndexQuery)
-		if err != nil {
-			return nil, err
-		}
+	return &Storage{DB: db, fullText: fullText}, nil
+}
 
-		log.Println("Database and table created successfully.")
+// OpenDatabase подключается к базе без применения миграций: source — путь
+// к файлу для SQLite или DSN для PostgreSQL.
+func OpenDatabase(dialect Dialect, source string) (*sql.DB, error) {
+	driver := sqliteDriver
+	if dialect == DialectPostgres {
+		driver = "postgres"
 	}
 
-	return &Storage{DB: db}, nil
+	db, err := sql.Open(driver, source)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// visibleTasks — условие на задачи, которые видит пользователь: его личные
+// задачи и задачи проектов, в которых он участвует. Параметры — id
+// пользователя дважды.
+const visibleTasks = `((project_id = 0 AND owner_id = ?) OR project_id IN (SELECT project_id FROM project_members WHERE user_id = ?))`
+
+// editableTasks — то же для задач, которые пользователь может изменять.
+const editableTasks = `((project_id = 0 AND owner_id = ?) OR project_id IN (SELECT project_id FROM project_members WHERE user_id = ? AND role IN ('owner', 'editor')))`
+
+// filteredTasks — условие TaskFilter. Параметры — id проекта дважды
+// и id исполнителя дважды.
+const filteredTasks = `(? = 0 OR project_id = ?) AND (? = 0 OR assignee_id = ?)`
+
+func filterArgs(filter TaskFilter) []any {
+	return []any{filter.ProjectID, filter.ProjectID, filter.AssigneeID, filter.AssigneeID}
+}
+
+func (s *Storage) GetUpcomingTasks(filter TaskFilter, limit int) ([]map[string]string, error) {
+	query := `SELECT ` + taskColumns + ` FROM scheduler WHERE ` + visibleTasks + ` AND ` + filteredTasks + ` ORDER BY date, due_time LIMIT ?`
+	args := append([]any{s.owner, s.owner}, filterArgs(filter)...)
+	return s.queryTasks(query, append(args, limit)...)
 }
 
-func (s *Storage) GetUpcomingTasks(limit int) ([]map[string]string, error) {
-	query := `SELECT id, date, title, comment, repeat FROM scheduler ORDER BY date LIMIT ?`
-	rows, err := s.DB.Query(query, limit)
+// SearchTasks сравнивает строки функцией lower из Go: встроенная LOWER
+// в SQLite не меняет регистр кириллицы.
+func (s *Storage) SearchTasks(text string, filter TaskFilter, limit int) ([]map[string]string, error) {
+	query := `SELECT ` + taskColumns + ` FROM scheduler
+	WHERE ` + visibleTasks + ` AND ` + filteredTasks + `
+	AND (lower(title) LIKE ? ESCAPE '\' OR lower(COALESCE(comment, '')) LIKE ? ESCAPE '\')
+	ORDER BY date, due_time LIMIT ?`
+	pattern := likePattern(text)
+	args := append([]any{s.owner, s.owner}, filterArgs(filter)...)
+	return s.queryTasks(query, append(args, pattern, pattern, limit)...)
+}
+
+func (s *Storage) GetTasksByDate(date string, filter TaskFilter, limit int) ([]map[string]string, error) {
+	query := `SELECT ` + taskColumns + ` FROM scheduler WHERE ` + visibleTasks + ` AND ` + filteredTasks + ` AND date = ? ORDER BY due_time LIMIT ?`
+	args := append([]any{s.owner, s.owner}, filterArgs(filter)...)
+	return s.queryTasks(query, append(args, date, limit)...)
+}
+
+func (s *Storage) queryTasks(query string, args ...any) ([]map[string]string, error) {
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("error querying tasks: %v", err)
 	}
@@ -72,20 +166,11 @@ func (s *Storage) GetUpcomingTasks(limit int) ([]map[string]string, error) {
 
 	for rows.Next() {
 		var task Task
-		if err := rows.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat); err != nil {
+		if err := rows.Scan(task.fields()...); err != nil {
 			log.Printf("Error scanning task: %v", err)
 			continue
 		}
-
-		taskMap := map[string]string{
-			"id":      strconv.FormatInt(task.ID, 10), // Преобразуем ID в строку
-			"date":    task.Date,
-			"title":   task.Title,
-			"comment": task.Comment,
-			"repeat":  task.Repeat,
-		}
-
-		tasks = append(tasks, taskMap)
+		tasks = append(tasks, task.toMap())
 	}
 
 	if err := rows.Err(); err != nil {
@@ -99,15 +184,29 @@ func (s *Storage) GetUpcomingTasks(limit int) ([]map[string]string, error) {
 	return tasks, nil
 }
 
+func (s *Storage) AddTask(task *Task) (int64, error) {
+	if err := checkPlacement(task, s.owner, s.ProjectRole); err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO scheduler (date, due_time, title, comment, repeat, repeat_mode, catchup, owner_id, project_id, assignee_id)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	res, err := s.DB.Exec(query, task.Date, task.Time, task.Title, task.Comment, task.Repeat, task.RepeatMode, task.CatchUp, s.owner, task.ProjectID, task.AssigneeID)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
 func (s *Storage) GetTaskByID(taskID int64) (*Task, error) {
-	query := `SELECT id, date, title, comment, repeat FROM scheduler WHERE id = ?`
+	query := `SELECT ` + taskColumns + ` FROM scheduler WHERE id = ? AND ` + visibleTasks
 	var task Task
 
 	// Выполняем запрос
-	err := s.DB.QueryRow(query, taskID).Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat)
+	err := s.DB.QueryRow(query, taskID, s.owner, s.owner).Scan(task.fields()...)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("задача не найдена")
+			return nil, ErrTaskNotFound
 		}
 		return nil, fmt.Errorf("ошибка при выполнении запроса: %v", err)
 	}
@@ -115,19 +214,330 @@ func (s *Storage) GetTaskByID(taskID int64) (*Task, error) {
 	return &task, nil
 }
 
-func (s *Storage) UpdateTask(id int64, date, title, comment, repeat string) error {
-    query := `UPDATE scheduler SET date=?, title=?, comment=?, repeat=? WHERE id=?`
-    _, err := s.DB.Exec(query, date, title, comment, repeat, id)
-    return err
+// checkEditable проверяет видимость задачи и роль в её проекте одним
+// запросом.
+func (s *Storage) checkEditable(id int64) error {
+	var editable bool
+	query := `SELECT ` + editableTasks + ` FROM scheduler WHERE id = ? AND ` + visibleTasks
+	err := s.DB.QueryRow(query, s.owner, s.owner, id, s.owner, s.owner).Scan(&editable)
+	if err == sql.ErrNoRows {
+		return ErrTaskNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !editable {
+		return ErrTaskReadOnly
+	}
+	return nil
+}
+
+func (s *Storage) UpdateTask(task *Task) error {
+	if err := s.checkEditable(task.ID); err != nil {
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+		return err
+	}
+	if err := checkPlacement(task, s.owner, s.ProjectRole); err != nil {
+		return err
+	}
+
+	query := `UPDATE scheduler SET date=?, due_time=?, title=?, comment=?, repeat=?, repeat_mode=?, catchup=?, project_id=?, assignee_id=?,
+	owner_id = CASE WHEN ? = 0 THEN ? ELSE owner_id END WHERE id=?`
+	_, err := s.DB.Exec(query, task.Date, task.Time, task.Title, task.Comment, task.Repeat, task.RepeatMode, task.CatchUp, task.ProjectID, task.AssigneeID,
+		task.ProjectID, s.owner, task.ID)
+	return err
+}
+
+func (s *Storage) UpdateTaskDate(id int64, date, repeat string) error {
+	if err := s.checkEditable(id); err != nil {
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	query := `UPDATE scheduler SET date = ?, repeat = ? WHERE id = ?`
+	_, err := s.DB.Exec(query, date, repeat, id)
+	return err
+}
+
+func (s *Storage) DeleteTask(id int64) error {
+	if err := s.checkEditable(id); err != nil {
+		return err
+	}
+
+	res, err := s.DB.Exec(`DELETE FROM scheduler WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+
+	if err := s.DeleteExceptions(id); err != nil {
+		log.Printf("Error deleting exceptions of task %d: %v", id, err)
+	}
+	return nil
 }
 
 func (s *Storage) TaskExists(id int64) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM scheduler WHERE id=?)`
-	err := s.DB.QueryRow(query, id).Scan(&exists)
+	query := `SELECT EXISTS(SELECT 1 FROM scheduler WHERE id=? AND ` + visibleTasks + `)`
+	err := s.DB.QueryRow(query, id, s.owner, s.owner).Scan(&exists)
 	return exists, err
 }
 
+// ForOwner возвращает хранилище с тем же подключением к базе.
+func (s *Storage) ForOwner(ownerID int64) TaskRepository {
+	return &Storage{DB: s.DB, fullText: s.fullText, owner: ownerID}
+}
+
 func (s *Storage) Close() error {
 	return s.DB.Close()
 }
+
+// Exception — исключённое повторение задачи: пропущенное (MovedTo пустой)
+// или перенесённое на другую дату.
+type Exception struct {
+	Date    string `json:"date"`
+	MovedTo string `json:"moved_to"`
+}
+
+func (s *Storage) AddException(taskID int64, date, movedTo string) error {
+	if err := s.checkEditable(taskID); err != nil {
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	query := `INSERT INTO task_exceptions (task_id, date, moved_to) VALUES (?, ?, ?)`
+	_, err := s.DB.Exec(query, taskID, date, movedTo)
+	return err
+}
+
+func (s *Storage) ResolveMove(taskID int64, date string) error {
+	if err := s.checkEditable(taskID); err != nil {
+		if errors.Is(err, ErrTaskNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	query := `UPDATE task_exceptions SET moved_to = '' WHERE task_id = ? AND date = ?`
+	_, err := s.DB.Exec(query, taskID, date)
+	return err
+}
+
+func (s *Storage) GetExceptions(taskID int64) ([]Exception, error) {
+	query := `SELECT date, moved_to FROM task_exceptions
+	WHERE task_id = ? AND EXISTS(SELECT 1 FROM scheduler WHERE id = task_id AND ` + visibleTasks + `) ORDER BY date`
+	rows, err := s.DB.Query(query, taskID, s.owner, s.owner)
+	if err != nil {
+		return nil, fmt.Errorf("error querying exceptions: %v", err)
+	}
+	defer rows.Close()
+
+	var exceptions []Exception
+	for rows.Next() {
+		var e Exception
+		if err := rows.Scan(&e.Date, &e.MovedTo); err != nil {
+			return nil, err
+		}
+		exceptions = append(exceptions, e)
+	}
+
+	return exceptions, rows.Err()
+}
+
+// Completion — запись о выполнении повторения задачи.
+type Completion struct {
+	TaskID      int64  `json:"task_id"`
+	Date        string `json:"date"`
+	CompletedAt string `json:"completed_at"`
+	Note        string `json:"note"`
+}
+
+func (s *Storage) CompleteTask(c Completion, nextDate, repeat string) error {
+	if err := s.checkEditable(c.TaskID); err != nil {
+		return err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `INSERT INTO task_completions (task_id, date, completed_at, note, owner_id, project_id)
+	SELECT id, ?, ?, ?, ?, project_id FROM scheduler WHERE id = ?`
+	if _, err := tx.Exec(query, c.Date, c.CompletedAt, c.Note, s.owner, c.TaskID); err != nil {
+		return err
+	}
+	if nextDate == "" {
+		if _, err := tx.Exec(`DELETE FROM scheduler WHERE id = ?`, c.TaskID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM task_exceptions WHERE task_id = ?`, c.TaskID); err != nil {
+			return err
+		}
+	} else if _, err := tx.Exec(`UPDATE scheduler SET date = ?, repeat = ? WHERE id = ?`, nextDate, repeat, c.TaskID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (s *Storage) GetCompletions(taskID int64) ([]Completion, error) {
+	query := `SELECT task_id, date, completed_at, note FROM task_completions
+	WHERE task_id = ? AND ` + visibleTasks + ` ORDER BY completed_at DESC, id DESC`
+	rows, err := s.DB.Query(query, taskID, s.owner, s.owner)
+	if err != nil {
+		return nil, fmt.Errorf("error querying completions: %v", err)
+	}
+	defer rows.Close()
+
+	completions := []Completion{}
+	for rows.Next() {
+		var c Completion
+		if err := rows.Scan(&c.TaskID, &c.Date, &c.CompletedAt, &c.Note); err != nil {
+			return nil, err
+		}
+		completions = append(completions, c)
+	}
+
+	return completions, rows.Err()
+}
+
+func (s *Storage) AddUser(user *User) (int64, error) {
+	query := `INSERT INTO users (login, password_hash, created_at) VALUES (?, ?, ?)`
+	res, err := s.DB.Exec(query, user.Login, user.PasswordHash, user.CreatedAt)
+	if err != nil {
+		// Код ошибки sqlite3.Error доступен только в сборке с CGO, а хранилище
+		// в памяти должно собираться и без него, поэтому проверяется текст.
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return 0, ErrUserExists
+		}
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func (s *Storage) GetUserByLogin(login string) (*User, error) {
+	query := `SELECT id, login, password_hash, created_at FROM users WHERE login = ?`
+	var user User
+	err := s.DB.QueryRow(query, login).Scan(&user.ID, &user.Login, &user.PasswordHash, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *Storage) AddProject(project *Project, ownerID int64) (int64, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT INTO projects (name, created_at) VALUES (?, ?)`, project.Name, project.CreatedAt)
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	query := `INSERT INTO project_members (project_id, user_id, role) VALUES (?, ?, ?)`
+	if _, err := tx.Exec(query, id, ownerID, RoleOwner); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit()
+}
+
+func (s *Storage) GetProjects(userID int64) ([]Project, error) {
+	query := `SELECT p.id, p.name, p.created_at, m.role FROM projects p
+	JOIN project_members m ON m.project_id = p.id WHERE m.user_id = ? ORDER BY p.name, p.id`
+	rows, err := s.DB.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying projects: %v", err)
+	}
+	defer rows.Close()
+
+	projects := []Project{}
+	for rows.Next() {
+		var p Project
+		if err := rows.Scan(&p.ID, &p.Name, &p.CreatedAt, &p.Role); err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+func (s *Storage) ProjectRole(projectID, userID int64) (Role, error) {
+	var role Role
+	query := `SELECT role FROM project_members WHERE project_id = ? AND user_id = ?`
+	err := s.DB.QueryRow(query, projectID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", ErrProjectNotFound
+	}
+	return role, err
+}
+
+func (s *Storage) SetMember(projectID, userID int64, role Role) error {
+	query := `INSERT INTO project_members (project_id, user_id, role) VALUES (?, ?, ?)
+	ON CONFLICT (project_id, user_id) DO UPDATE SET role = excluded.role`
+	_, err := s.DB.Exec(query, projectID, userID, role)
+	return err
+}
+
+func (s *Storage) RemoveMember(projectID, userID int64) error {
+	res, err := s.DB.Exec(`DELETE FROM project_members WHERE project_id = ? AND user_id = ?`, projectID, userID)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (s *Storage) GetMembers(projectID int64) ([]ProjectMember, error) {
+	query := `SELECT m.user_id, COALESCE(u.login, ''), m.role FROM project_members m
+	LEFT JOIN users u ON u.id = m.user_id WHERE m.project_id = ? ORDER BY m.user_id`
+	rows, err := s.DB.Query(query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying project members: %v", err)
+	}
+	defer rows.Close()
+
+	members := []ProjectMember{}
+	for rows.Next() {
+		var m ProjectMember
+		if err := rows.Scan(&m.UserID, &m.Login, &m.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (s *Storage) DeleteExceptions(taskID int64) error {
+	_, err := s.DB.Exec(`DELETE FROM task_exceptions WHERE task_id = ?`, taskID)
+	return err
+}
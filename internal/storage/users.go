@@ -0,0 +1,33 @@
+package storage
+
+import "errors"
+
+var (
+	// ErrUserNotFound возвращается, если пользователя с таким логином нет.
+	ErrUserNotFound = errors.New("пользователь не найден")
+	// ErrUserExists возвращается при регистрации занятого логина.
+	ErrUserExists = errors.New("логин уже занят")
+)
+
+// User — учётная запись. PasswordHash — хеш bcrypt, пароль не хранится.
+type User struct {
+	ID           int64  `json:"id"`
+	Login        string `json:"login"`
+	PasswordHash string `json:"-"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// UserRepository — хранилище учётных записей. Его реализуют все
+// хранилища задач.
+type UserRepository interface {
+	// AddUser сохраняет пользователя и возвращает его id. Если логин занят,
+	// возвращается ErrUserExists.
+	AddUser(user *User) (int64, error)
+	GetUserByLogin(login string) (*User, error)
+}
+
+var (
+	_ UserRepository = (*Storage)(nil)
+	_ UserRepository = (*PostgresStorage)(nil)
+	_ UserRepository = (*MemoryStorage)(nil)
+)
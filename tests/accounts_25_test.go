@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/handlers"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+func register(t *testing.T, url, login, password string) (int, string) {
+	code, m := authRequest(t, url+"/api/register", http.MethodPost, "", map[string]any{
+		"login":    login,
+		"password": password,
+	})
+	token, _ := m["token"].(string)
+	return code, token
+}
+
+// TestAccounts проверяет, что пользователи видят и изменяют только свои
+// задачи, а чужие для них не существуют.
+func TestAccounts(t *testing.T) {
+	stores := map[string]interface {
+		storage.TaskRepository
+		storage.UserRepository
+	}{
+		"memory": storage.NewMemoryStorage(),
+	}
+	if sqlite := sqliteStorage(t, "accounts.db"); sqlite != nil {
+		stores["sqlite"] = sqlite
+	}
+
+	for name, db := range stores {
+		t.Run(name, func(t *testing.T) {
+			testAccounts(t, db, db)
+		})
+	}
+}
+
+func testAccounts(t *testing.T, db storage.TaskRepository, users storage.UserRepository) {
+	auth, err := handlers.NewAccountsAuth(users, "secret", nil)
+	assert.NoError(t, err)
+	server := httptest.NewServer(handlers.NewRouter(db, scheduler.SystemClock{}, "../web", auth))
+	defer server.Close()
+
+	code, alice := register(t, server.URL, "alice", "alice-password")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, alice)
+
+	code, _ = register(t, server.URL, "alice", "another-password")
+	assert.Equal(t, http.StatusConflict, code)
+	code, _ = register(t, server.URL, "carol", "123")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = signIn(t, server.URL, "alice-password")
+	assert.Equal(t, http.StatusUnauthorized, code, "вход без логина")
+	code, m := authRequest(t, server.URL+"/api/signin", http.MethodPost, "", map[string]any{
+		"login": "bob", "password": "bob-password",
+	})
+	assert.Equal(t, http.StatusUnauthorized, code, "вход до регистрации")
+
+	code, bob := register(t, server.URL, "bob", "bob-password")
+	assert.Equal(t, http.StatusOK, code)
+	code, m = authRequest(t, server.URL+"/api/signin", http.MethodPost, "", map[string]any{
+		"login": "bob", "password": "bob-password",
+	})
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, m["token"])
+
+	code, m = authRequest(t, server.URL+"/api/task", http.MethodPost, alice, map[string]any{
+		"title":  "Задача Алисы",
+		"repeat": "d 1",
+	})
+	assert.Equal(t, http.StatusOK, code)
+	id := fmt.Sprint(m["id"])
+
+	code, m = authRequest(t, server.URL+"/api/tasks", http.MethodGet, alice, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 1)
+
+	code, m = authRequest(t, server.URL+"/api/tasks", http.MethodGet, bob, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 0)
+	code, m = authRequest(t, server.URL+"/api/tasks?search=Алисы", http.MethodGet, bob, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 0)
+	code, m = authRequest(t, server.URL+"/api/search?q=Алисы", http.MethodGet, bob, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 0)
+
+	// Чужая задача для Боба не существует.
+	for _, req := range []struct {
+		method string
+		path   string
+		body   map[string]any
+	}{
+		{http.MethodGet, "/api/task?id=" + id, nil},
+		{http.MethodPut, "/api/task", map[string]any{"id": id, "title": "Чужая", "date": "20240101"}},
+		{http.MethodPost, "/api/task/done?id=" + id, nil},
+		{http.MethodGet, "/api/task/history?id=" + id, nil},
+		{http.MethodDelete, "/api/task?id=" + id, nil},
+	} {
+		code, _ := authRequest(t, server.URL+req.path, req.method, bob, req.body)
+		assert.Equal(t, http.StatusNotFound, code, req.method+" "+req.path)
+	}
+
+	code, m = authRequest(t, server.URL+"/api/task?id="+id, http.MethodGet, alice, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Задача Алисы", m["title"])
+
+	code, _ = authRequest(t, server.URL+"/api/tasks", http.MethodGet, "", nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
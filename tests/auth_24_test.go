@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/handlers"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+// authRequest отправляет запрос к серверу с токеном в cookie (если он задан)
+// и возвращает код ответа и разобранный JSON.
+func authRequest(t *testing.T, url, method, token string, values map[string]any) (int, map[string]any) {
+	var body bytes.Buffer
+	if values != nil {
+		assert.NoError(t, json.NewEncoder(&body).Encode(values))
+	}
+	req, err := http.NewRequest(method, url, &body)
+	assert.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.AddCookie(&http.Cookie{Name: "token", Value: token})
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var m map[string]any
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
+	return resp.StatusCode, m
+}
+
+func signIn(t *testing.T, url, password string) (int, string) {
+	code, m := authRequest(t, url+"/api/signin", http.MethodPost, "", map[string]any{"password": password})
+	token, _ := m["token"].(string)
+	return code, token
+}
+
+func TestAuth(t *testing.T) {
+	db := storage.NewMemoryStorage()
+	clock := scheduler.NewFixedClock(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+	server := httptest.NewServer(handlers.NewRouter(db, clock, "../web", handlers.NewAuth("secret", clock)))
+	defer server.Close()
+
+	code, m := authRequest(t, server.URL+"/api/tasks", http.MethodGet, "", nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.NotEmpty(t, m["error"])
+
+	code, token := signIn(t, server.URL, "wrong")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Empty(t, token)
+
+	code, token = signIn(t, server.URL, "secret")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotEmpty(t, token)
+
+	// В токене нет ничего, что зависит от пароля.
+	parts := strings.Split(token, ".")
+	if assert.Len(t, parts, 3) {
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		assert.NoError(t, err)
+		var claims map[string]any
+		assert.NoError(t, json.Unmarshal(payload, &claims))
+		assert.Contains(t, claims, "exp")
+		assert.NotContains(t, claims, "pwd")
+		assert.Len(t, claims, 1)
+	}
+
+	code, m = authRequest(t, server.URL+"/api/task", http.MethodPost, token, map[string]any{"title": "Защищённая задача"})
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, m["id"])
+
+	code, _ = authRequest(t, server.URL+"/api/tasks", http.MethodGet, token, nil)
+	assert.Equal(t, http.StatusOK, code)
+
+	// Подделанный токен не принимается.
+	code, _ = authRequest(t, server.URL+"/api/tasks", http.MethodGet, token+"x", nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// Токен действует ограниченное время.
+	clock.Advance(9 * time.Hour)
+	code, _ = authRequest(t, server.URL+"/api/tasks", http.MethodGet, token, nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+// TestAuthHeader проверяет, что токен из заголовка Authorization
+// принимается и тогда, когда в cookie лежит недействительный токен.
+func TestAuthHeader(t *testing.T) {
+	db := storage.NewMemoryStorage()
+	server := httptest.NewServer(handlers.NewRouter(db, scheduler.SystemClock{}, "../web", handlers.NewAuth("secret", nil)))
+	defer server.Close()
+
+	code, token := signIn(t, server.URL, "secret")
+	assert.Equal(t, http.StatusOK, code)
+
+	tbl := []struct {
+		header string
+		cookie string
+		want   int
+	}{
+		{"Bearer " + token, "", http.StatusOK},
+		{"Bearer " + token, "stale", http.StatusOK},
+		{"Bearer stale", token, http.StatusOK},
+		{"Bearer stale", "", http.StatusUnauthorized},
+		{"Bearer stale", "stale", http.StatusUnauthorized},
+	}
+	for _, v := range tbl {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/tasks", nil)
+		assert.NoError(t, err)
+		req.Header.Set("Authorization", v.header)
+		if v.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "token", Value: v.cookie})
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, v.want, resp.StatusCode, "%q, cookie %q", v.header, v.cookie)
+		}
+	}
+}
+
+// TestAuthPasswordChange проверяет, что после смены пароля старые токены
+// перестают действовать.
+func TestAuthPasswordChange(t *testing.T) {
+	db := storage.NewMemoryStorage()
+	clock := scheduler.NewFixedClock(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC))
+
+	oldServer := httptest.NewServer(handlers.NewRouter(db, clock, "../web", handlers.NewAuth("old", clock)))
+	defer oldServer.Close()
+	code, token := signIn(t, oldServer.URL, "old")
+	assert.Equal(t, http.StatusOK, code)
+
+	newServer := httptest.NewServer(handlers.NewRouter(db, clock, "../web", handlers.NewAuth("new", clock)))
+	defer newServer.Close()
+	code, _ = authRequest(t, newServer.URL+"/api/tasks", http.MethodGet, token, nil)
+	assert.Equal(t, http.StatusUnauthorized, code)
+}
+
+// TestAuthDisabled проверяет, что без пароля API доступно без входа.
+func TestAuthDisabled(t *testing.T) {
+	db := storage.NewMemoryStorage()
+	server := httptest.NewServer(handlers.NewRouter(db, scheduler.SystemClock{}, "../web", handlers.NewAuth("", nil)))
+	defer server.Close()
+
+	code, _ := authRequest(t, server.URL+"/api/tasks", http.MethodGet, "", nil)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = signIn(t, server.URL, "any")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
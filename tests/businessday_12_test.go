@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateBusinessDay(t *testing.T) {
+	tbl := []nextDate{
+		{"20240126", "bd", ""},
+		{"20240126", "bd 0", ""},
+		{"20240126", "bd 2 3", ""},
+		{"20240126", "bd 251", ""},
+		{"20240126", "m 3 shift", ""},
+		{"20240126", "m 3 shift sideways", ""},
+		{"20240126", "m 3 shift next shift prev", ""},
+		{"20240126", "bd 1", "20240129"},
+		{"20240129", "bd 1", "20240130"},
+		{"20240126", "bd 3", "20240131"},
+		{"20240119", "bd 5", "20240202"},
+		{"20240126", "m 1 shift next", "20240201"},
+		{"20240126", "m 3 shift next", "20240205"},
+		{"20240126", "m 3 shift prev", "20240202"},
+		{"20240126", "m 27 shift prev", "20240227"},
+		{"20240126", "w 6 shift next", "20240129"},
+		{"20240126", "d 1 shift prev", "20240129"},
+		{"20240126", "m 3 count 2 shift next", "20240205"},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if len(v.want) == 0 {
+			assert.Error(t, err, "Ожидается ошибка для %v", v)
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
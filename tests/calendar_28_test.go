@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/scheduler"
+)
+
+// TestLoadCalendar проверяет загрузку производственного календаря из
+// поддерживаемых форматов и отказ от файлов с несуществующими датами.
+func TestLoadCalendar(t *testing.T) {
+	tbl := []struct {
+		name     string
+		file     string
+		data     string
+		holidays []string
+		workdays []string
+	}{
+		{
+			name:     "простой JSON",
+			file:     "calendar.json",
+			data:     `{"holidays": ["20240308"], "workdays": ["20240302"]}`,
+			holidays: []string{"20240308", "20240303"},
+			workdays: []string{"20240302", "20240307", "20240311"},
+		},
+		{
+			name: "xmlcalendar.ru",
+			file: "calendar.json",
+			data: `{"year": 2024, "months": [
+				{"month": 1, "days": "1,2,3,4,5,6,7,8,13,14,20,21,27,28"},
+				{"month": 2, "days": "3,4,10,11,17,18,22*,23,24,25"},
+				{"month": 4, "days": "6,7,13,14,20,21,28,29+,30+"}
+			]}`,
+			holidays: []string{"20240101", "20240108", "20240113", "20240223", "20240428", "20240429", "20240430"},
+			workdays: []string{"20240109", "20240222", "20240226", "20240427"},
+		},
+		{
+			name: "iCalendar",
+			file: "calendar.ics",
+			data: "BEGIN:VCALENDAR\r\n" +
+				"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20240101\r\nDTEND;VALUE=DATE:20240103\r\nSUMMARY:Новый год\r\nEND:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20240612\r\nSUMMARY:День России\r\nEND:VEVENT\r\n" +
+				"END:VCALENDAR\r\n",
+			holidays: []string{"20240101", "20240102", "20240612", "20240106"},
+			workdays: []string{"20240103", "20240611", "20240613"},
+		},
+		{name: "несуществующая дата", file: "calendar.json", data: `{"holidays": ["20240230"]}`},
+		{name: "31 февраля", file: "calendar.json", data: `{"year": 2024, "months": [{"month": 2, "days": "31"}]}`},
+		{name: "30 февраля", file: "calendar.json", data: `{"year": 2024, "months": [{"month": 2, "days": "30+"}]}`},
+		{name: "нулевой день", file: "calendar.json", data: `{"year": 2024, "months": [{"month": 5, "days": "0"}]}`},
+		{name: "13-й месяц", file: "calendar.json", data: `{"year": 2024, "months": [{"month": 13, "days": "1"}]}`},
+		{name: "не число", file: "calendar.json", data: `{"year": 2024, "months": [{"month": 1, "days": "1,x"}]}`},
+		{name: "битый JSON", file: "calendar.json", data: `{"holidays": [`},
+		{name: "битый iCalendar", file: "calendar.ics", data: "BEGIN:VEVENT\nDTSTART:2024\nEND:VEVENT\n"},
+		{name: "неизвестный формат", file: "calendar.txt", data: "20240101"},
+	}
+
+	for _, v := range tbl {
+		t.Run(v.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), v.file)
+			assert.NoError(t, os.WriteFile(path, []byte(v.data), 0o644))
+
+			c, err := scheduler.LoadCalendar(path)
+			if v.holidays == nil {
+				assert.Error(t, err)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, s := range v.holidays {
+				d, _ := time.Parse("20060102", s)
+				assert.False(t, c.IsWorkday(d), s)
+			}
+			for _, s := range v.workdays {
+				d, _ := time.Parse("20060102", s)
+				assert.True(t, c.IsWorkday(d), s)
+			}
+		})
+	}
+}
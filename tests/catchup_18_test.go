@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// taskDates возвращает даты, на которых задача id показана в списке.
+func taskDates(t *testing.T, id string) []string {
+	body, err := requestJSON("api/tasks?limit=1000", nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+
+	var dates []string
+	for _, task := range m["tasks"] {
+		if task["id"] == id {
+			dates = append(dates, task["date"])
+		}
+	}
+	return dates
+}
+
+func TestCatchUp(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	id := addTask(t, task{
+		date:  day(1),
+		title: "Лекарство",
+	})
+	ret, err := postJSON("api/task", map[string]any{
+		"id":      id,
+		"date":    day(1),
+		"title":   "Лекарство",
+		"repeat":  "d 1",
+		"catchup": "never",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	tbl := []struct {
+		catchup string
+		repeat  string
+		list    []string
+		done    string
+	}{
+		{"", "d 2", []string{day(-3)}, day(1)},
+		{"oldest", "d 2", []string{day(-3)}, day(1)},
+		{"skip", "d 2", []string{day(1)}, day(3)},
+		{"skip", "d 3", []string{day(0)}, day(3)},
+		{"each", "d 1", []string{day(-3), day(-2), day(-1), day(0)}, day(-2)},
+		{"each", "d 2", []string{day(-3), day(-1)}, day(-1)},
+	}
+	for _, v := range tbl {
+		ret, err := postJSON("api/task", map[string]any{
+			"id":      id,
+			"date":    day(-3),
+			"title":   "Лекарство",
+			"repeat":  v.repeat,
+			"catchup": v.catchup,
+		}, http.MethodPut)
+		assert.NoError(t, err)
+		assert.Empty(t, ret["error"])
+
+		assert.Equal(t, v.list, taskDates(t, id), "catchup %q, repeat %q", v.catchup, v.repeat)
+
+		ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+		assert.Equal(t, v.done, getTaskDate(t, id), "catchup %q, repeat %q", v.catchup, v.repeat)
+
+		// В историю записывается выполненное повторение из списка.
+		if history := getHistory(t, id); assert.NotEmpty(t, history) {
+			assert.Equal(t, v.list[0], history[0]["date"], "catchup %q, repeat %q", v.catchup, v.repeat)
+		}
+	}
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/handlers"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+// serve вызывает обработчик напрямую и возвращает разобранный JSON-ответ.
+func serve(t *testing.T, h http.HandlerFunc, method, target string, values map[string]any) map[string]any {
+	var body bytes.Buffer
+	if values != nil {
+		assert.NoError(t, json.NewEncoder(&body).Encode(values))
+	}
+	r := httptest.NewRequest(method, target, &body)
+	r.Header.Set("X-Timezone", "UTC")
+	w := httptest.NewRecorder()
+	h(w, r)
+
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &m), w.Body.String())
+	return m
+}
+
+// TestClockMidnight проверяет переход через полночь на остановленных часах,
+// не завися от текущей даты.
+func TestClockMidnight(t *testing.T) {
+	db := storage.NewMemoryStorage()
+
+	clock := scheduler.NewFixedClock(time.Date(2024, 1, 31, 23, 59, 0, 0, time.UTC))
+	h := &handlers.Handler{Storage: db, Clock: clock}
+
+	ret := serve(t, handlers.AddTaskHandler(db, clock), http.MethodPost, "/api/task", map[string]any{
+		"title":  "Дневник",
+		"repeat": "d 1",
+	})
+	assert.NotNil(t, ret["id"])
+	id := int64(ret["id"].(float64))
+
+	task, err := db.GetTaskByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "20240131", task.Date)
+
+	// Через две минуты наступает 1 февраля: выполнение переносит
+	// задачу на первую дату после новых «сегодня».
+	clock.Advance(2 * time.Minute)
+	ret = serve(t, handlers.DoneTaskHandler(db, clock), http.MethodPost, "/api/task/done?id="+strconv.FormatInt(id, 10), nil)
+	assert.Empty(t, ret)
+
+	task, err = db.GetTaskByID(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "20240202", task.Date)
+
+	// Через неделю задача просрочена, а с политикой "each" каждое
+	// пропущенное повторение показывается отдельно.
+	clock.Set(time.Date(2024, 2, 5, 12, 0, 0, 0, time.UTC))
+	task.CatchUp = string(scheduler.CatchUpEach)
+	assert.NoError(t, db.UpdateTask(task))
+
+	ret = serve(t, h.TasksHandler, http.MethodGet, "/api/tasks", nil)
+	var dates []any
+	for _, v := range ret["tasks"].([]any) {
+		dates = append(dates, v.(map[string]any)["date"])
+	}
+	assert.Equal(t, []any{"20240202", "20240203", "20240204", "20240205"}, dates)
+}
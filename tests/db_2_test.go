@@ -1,29 +1,56 @@
 package tests
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 )
 
 type Task struct {
-	ID      int64  `db:"id"`
-	Date    string `db:"date"`
-	Title   string `db:"title"`
-	Comment string `db:"comment"`
-	Repeat  string `db:"repeat"`
+	ID         int64  `db:"id"`
+	Date       string `db:"date"`
+	Time       string `db:"due_time"`
+	Title      string `db:"title"`
+	Comment    string `db:"comment"`
+	Repeat     string `db:"repeat"`
+	RepeatMode string `db:"repeat_mode"`
+	CatchUp    string `db:"catchup"`
+	OwnerID    int64  `db:"owner_id"`
+	ProjectID  int64  `db:"project_id"`
+	AssigneeID int64  `db:"assignee_id"`
 }
 
-func count(db *sqlx.DB) (int, error) {
-	var count int
-	return count, db.Get(&count, `SELECT count(id) FROM scheduler`)
+// taskDB — база данных сервера. С хранилищем в памяти базы нет,
+// и задачи читаются и изменяются через API.
+type taskDB struct {
+	*sqlx.DB
 }
 
-func openDB(t *testing.T) *sqlx.DB {
+func openDB(t *testing.T) *taskDB {
+	if inMemory() {
+		return &taskDB{}
+	}
+
+	databaseURL := DatabaseURL
+	if envURL := os.Getenv("TODO_DATABASE_URL"); len(envURL) > 0 {
+		databaseURL = envURL
+	}
+	if len(databaseURL) > 0 {
+		db, err := sqlx.Connect("postgres", databaseURL)
+		assert.NoError(t, err)
+		return &taskDB{db}
+	}
+
 	dbfile := DBFile
 	envFile := os.Getenv("TODO_DBFILE")
 	if len(envFile) > 0 {
@@ -31,35 +58,135 @@ func openDB(t *testing.T) *sqlx.DB {
 	}
 	db, err := sqlx.Connect("sqlite3", dbfile)
 	assert.NoError(t, err)
-	return db
+	return &taskDB{db}
+}
+
+func (db *taskDB) Close() error {
+	if db.DB == nil {
+		return nil
+	}
+	return db.DB.Close()
+}
+
+// getTask возвращает задачу id. Через API не читается только OwnerID.
+func (db *taskDB) getTask(id any) (Task, error) {
+	var task Task
+	if db.DB != nil {
+		err := db.Get(&task, db.Rebind(`SELECT * FROM scheduler WHERE id=?`), id)
+		return task, err
+	}
+
+	body, err := requestJSON(fmt.Sprint("api/task?id=", id), nil, http.MethodGet)
+	if err != nil {
+		return task, err
+	}
+	var m map[string]string
+	if err := json.Unmarshal(body, &m); err != nil {
+		return task, err
+	}
+	if len(m["error"]) > 0 {
+		return task, errors.New(m["error"])
+	}
+	task.ID, _ = strconv.ParseInt(m["id"], 10, 64)
+	task.ProjectID, _ = strconv.ParseInt(m["project_id"], 10, 64)
+	task.AssigneeID, _ = strconv.ParseInt(m["assignee_id"], 10, 64)
+	task.Date, task.Time, task.Title, task.Comment = m["date"], m["time"], m["title"], m["comment"]
+	task.Repeat, task.RepeatMode, task.CatchUp = m["repeat"], m["repeat_mode"], m["catchup"]
+	return task, nil
+}
+
+// allTasks возвращает id всех задач.
+func (db *taskDB) allTasks() ([]string, error) {
+	var ids []string
+	if db.DB != nil {
+		return ids, db.Select(&ids, `SELECT id FROM scheduler`)
+	}
+
+	body, err := requestJSON("api/tasks?limit=1000000", nil, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string][]map[string]string
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+	for _, task := range m["tasks"] {
+		ids = append(ids, task["id"])
+	}
+	return ids, nil
+}
+
+func (db *taskDB) count() (int, error) {
+	ids, err := db.allTasks()
+	return len(ids), err
+}
+
+func (db *taskDB) insertTask(date, title, comment string) (int64, error) {
+	var id int64
+	if db.DB != nil {
+		err := db.Get(&id, db.Rebind(`INSERT INTO scheduler (date, title, comment, repeat) 
+	VALUES (?, ?, ?, '') RETURNING id`), date, title, comment)
+		return id, err
+	}
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":    date,
+		"title":   title,
+		"comment": comment,
+	}, http.MethodPost)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(fmt.Sprint(m["id"]), 10, 64)
+}
+
+func (db *taskDB) deleteTask(id any) error {
+	if db.DB != nil {
+		_, err := db.Exec(db.Rebind(`DELETE FROM scheduler WHERE id = ?`), id)
+		return err
+	}
+	_, err := postJSON(fmt.Sprint("api/task?id=", id), nil, http.MethodDelete)
+	return err
+}
+
+// deleteAll удаляет все задачи.
+func (db *taskDB) deleteAll() error {
+	if db.DB != nil {
+		_, err := db.Exec("DELETE FROM scheduler")
+		return err
+	}
+
+	ids, err := db.allTasks()
+	for _, id := range ids {
+		if err == nil {
+			err = db.deleteTask(id)
+		}
+	}
+	return err
 }
 
 func TestDB(t *testing.T) {
 	db := openDB(t)
 	defer db.Close()
 
-	before, err := count(db)
+	before, err := db.count()
 	assert.NoError(t, err)
 
 	today := time.Now().Format(`20060102`)
 
-	res, err := db.Exec(`INSERT INTO scheduler (date, title, comment, repeat) 
-	VALUES (?, 'Todo', 'Комментарий', '')`, today)
+	id, err := db.insertTask(today, `Todo`, `Комментарий`)
 	assert.NoError(t, err)
 
-	id, err := res.LastInsertId()
-
-	var task Task
-	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	task, err := db.getTask(id)
 	assert.NoError(t, err)
 	assert.Equal(t, id, task.ID)
 	assert.Equal(t, `Todo`, task.Title)
 	assert.Equal(t, `Комментарий`, task.Comment)
 
-	_, err = db.Exec(`DELETE FROM scheduler WHERE id = ?`, id)
+	err = db.deleteTask(id)
 	assert.NoError(t, err)
 
-	after, err := count(db)
+	after, err := db.count()
 	assert.NoError(t, err)
 
 	assert.Equal(t, before, after)
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fullTextSearch(t *testing.T, query string) []map[string]string {
+	body, err := requestJSON("api/search?q="+url.QueryEscape(query), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]map[string]string
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["tasks"]
+}
+
+func TestFullTextSearch(t *testing.T) {
+	date := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	feed := addTask(t, task{
+		date:    date,
+		title:   "Кормление зебры",
+		comment: "Зебры любят морковь и яблоки",
+	})
+	buy := addTask(t, task{
+		date:    date,
+		title:   "Купить морковь",
+		comment: "Для зебры, свежую",
+	})
+
+	ids := func(tasks []map[string]string) []string {
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task["id"])
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []string{feed, buy}, ids(fullTextSearch(t, "зебр*")))
+	assert.ElementsMatch(t, []string{feed, buy}, ids(fullTextSearch(t, "ЗЕБРЫ")))
+	assert.Equal(t, []string{feed}, ids(fullTextSearch(t, `"любят морковь"`)))
+	assert.Equal(t, []string{feed}, ids(fullTextSearch(t, "морковь яблоки")))
+	assert.Empty(t, fullTextSearch(t, `"морковь любят"`))
+	assert.Empty(t, fullTextSearch(t, `"`))
+
+	tasks := fullTextSearch(t, `"любят морковь" кормление`)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, "<b>Кормление</b> зебры", tasks[0]["highlight"])
+		assert.Contains(t, tasks[0]["snippet"], "<b>любят морковь</b>")
+	}
+
+	ret, err := postJSON("api/search?q=", nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	for _, id := range []string{feed, buy} {
+		ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+	assert.Empty(t, fullTextSearch(t, "зебр*"))
+}
+
+// TestSearchEscaping проверяет, что текст задачи в highlight и snippet
+// экранирован для HTML и теги есть только вокруг совпадений.
+func TestSearchEscaping(t *testing.T) {
+	id := addTask(t, task{
+		date:    time.Now().AddDate(0, 0, 1).Format(`20060102`),
+		title:   "Сравнить a<b & c",
+		comment: `<img src=x onerror="alert(1)"> сравнить`,
+	})
+
+	tasks := fullTextSearch(t, "сравнить")
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, "<b>Сравнить</b> a&lt;b &amp; c", tasks[0]["highlight"])
+		assert.Equal(t, "Сравнить a<b & c", tasks[0]["title"])
+		assert.NotContains(t, tasks[0]["snippet"], "<img")
+		assert.Contains(t, tasks[0]["snippet"], "&lt;img")
+		assert.Contains(t, tasks[0]["snippet"], "<b>сравнить</b>")
+	}
+
+	ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
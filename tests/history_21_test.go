@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getHistory(t *testing.T, id string) []map[string]any {
+	body, err := requestJSON("api/task/history?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string][]map[string]any
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m["completions"]
+}
+
+func TestHistory(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	id := addTask(t, task{
+		date:   day(0),
+		title:  "Полить цветы",
+		repeat: "d 7",
+	})
+	assert.Empty(t, getHistory(t, id))
+
+	ret, err := postJSON("api/task/done?id="+id, map[string]any{"note": "Фикус тоже"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	history := getHistory(t, id)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, day(7), history[0]["date"])
+		assert.Equal(t, "", history[0]["note"])
+		assert.Equal(t, day(0), history[1]["date"])
+		assert.Equal(t, "Фикус тоже", history[1]["note"])
+		assert.NotEmpty(t, history[1]["completed_at"])
+	}
+
+	// История разовой задачи остаётся после её удаления.
+	id = addTask(t, task{
+		date:  day(1),
+		title: "Забрать посылку",
+	})
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+
+	history = getHistory(t, id)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, day(1), history[0]["date"])
+	}
+
+	for _, v := range []string{"", "abc", "99999999"} {
+		ret, err = postJSON("api/task/history?id="+v, nil, http.MethodGet)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], v)
+	}
+}
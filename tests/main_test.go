@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"todo-app/internal/handlers"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+// inMemory сообщает, что тесты запускают сервер сами, с хранилищем в памяти.
+func inMemory() bool {
+	return InMemory || os.Getenv("TODO_STORAGE") == "memory"
+}
+
+// TestMain в режиме inMemory поднимает сервер внутри процесса тестов,
+// поэтому не нужны ни запущенный сервер, ни файл базы данных. Тесты,
+// которые читают базу напрямую, в этом режиме читают задачи через API.
+func TestMain(m *testing.M) {
+	if inMemory() {
+		server := httptest.NewServer(handlers.NewRouter(storage.NewMemoryStorage(), scheduler.SystemClock{}, "../web", nil))
+		u, err := url.Parse(server.URL)
+		if err != nil {
+			panic(err)
+		}
+		os.Setenv("TODO_PORT", u.Port())
+
+		code := m.Run()
+		server.Close()
+		os.Exit(code)
+	}
+
+	os.Exit(m.Run())
+}
+
+// sqliteStorage открывает хранилище SQLite во временном каталоге теста.
+// Без CGO драйвер SQLite не работает, и тогда возвращается nil.
+func sqliteStorage(t *testing.T, name string) *storage.Storage {
+	file := filepath.Join(t.TempDir(), name)
+	probe, err := storage.OpenDatabase(storage.DialectSQLite, file)
+	if err != nil {
+		t.Logf("SQLite недоступна: %v", err)
+		return nil
+	}
+	probe.Close()
+
+	db, err := storage.NewStorage(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/storage"
+)
+
+func TestMigrate(t *testing.T) {
+	migrations, err := storage.Migrations(storage.DialectSQLite)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, migrations)
+	last := migrations[len(migrations)-1].Version
+
+	// База первой версии приложения, созданная до появления миграций.
+	dbFile := filepath.Join(t.TempDir(), "scheduler.db")
+	db, err := storage.OpenDatabase(storage.DialectSQLite, dbFile)
+	if err != nil {
+		// Без CGO драйвер SQLite не работает.
+		t.Skip(err)
+	}
+	_, err = db.Exec(`CREATE TABLE scheduler (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date TEXT NOT NULL,
+		title TEXT NOT NULL,
+		comment TEXT,
+		repeat TEXT
+	);
+	INSERT INTO scheduler (date, title, comment, repeat) VALUES ('20240126', 'Старая задача', '', 'd 5');`)
+	assert.NoError(t, err)
+
+	pending, err := storage.PendingMigrations(db, storage.DialectSQLite)
+	assert.NoError(t, err)
+	assert.Len(t, pending, len(migrations)-1)
+	assert.Equal(t, 2, pending[0].Version)
+
+	// Пробный прогон ничего не меняет.
+	pending, err = storage.PendingMigrations(db, storage.DialectSQLite)
+	assert.NoError(t, err)
+	assert.Len(t, pending, len(migrations)-1)
+	db.Close()
+
+	repo, err := storage.NewStorage(dbFile)
+	assert.NoError(t, err)
+	defer repo.Close()
+
+	task, err := repo.GetTaskByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Старая задача", task.Title)
+	assert.Equal(t, "fixed", task.RepeatMode)
+	assert.Equal(t, "oldest", task.CatchUp)
+
+	var version int
+	err = repo.DB.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version)
+	assert.NoError(t, err)
+	assert.Equal(t, last, version)
+
+	pending, err = storage.PendingMigrations(repo.DB, storage.DialectSQLite)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	// Повторный запуск не применяет миграции заново.
+	assert.NoError(t, storage.Migrate(repo.DB, storage.DialectSQLite))
+}
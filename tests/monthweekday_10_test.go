@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateMonthWeekday(t *testing.T) {
+	tbl := []nextDate{
+		{"20240126", "mw", ""},
+		{"20240126", "mw 2", ""},
+		{"20240126", "mw 0 1", ""},
+		{"20240126", "mw 6 1", ""},
+		{"20240126", "mw -6 2", ""},
+		{"20240126", "mw 1 8", ""},
+		{"20240126", "mw 1 0", ""},
+		{"20240126", "mw 1 1 13", ""},
+		{"20240126", "mw 1 1 1 1", ""},
+		{"20240126", "mw 2 2", "20240213"},
+		{"20240126", "mw -1 5", "20240223"},
+		{"20240126", "mw 1 1", "20240205"},
+		{"20240126", "mw 1,3 1", "20240205"},
+		{"20240205", "mw 1,3 1", "20240219"},
+		{"20240126", "mw 5 4", "20240229"},
+		{"20240126", "mw 5 1", "20240129"},
+		{"20240126", "mw -2 3", "20240221"},
+		{"20240126", "mw -1 7 12", "20241229"},
+		{"20240126", "mw 2 2 3,6", "20240312"},
+		{"20240126", "mw 5 1 2", ""},
+		{"20240126", "mw 4,5 1 2", "20240226"},
+		{"20240126", "mw 5 1 2,4", "20240429"},
+		{"20230101", "mw 1 1", "20240205"},
+		{"20240409", "mw 4 5", "20240426"},
+		{"20240409", "mw -1 5", "20240426"},
+		{"20240126", "mw 1 6,7", "20240203"},
+		{"20240126", "mw 2 1 count 1", ""},
+		{"20240126", "mw 2 1 until 20240210", ""},
+		{"20240126", "mw 2 1 until 20240212", "20240212"},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if len(v.want) == 0 {
+			assert.Error(t, err, "Ожидается ошибка для %v", v)
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
+
+func TestTaskMonthWeekday(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		date:   "20240126",
+		title:  "Ревью спринта",
+		repeat: "mw -1 5",
+	})
+
+	task, err := db.getTask(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "mw -1 5", task.Repeat)
+	assert.GreaterOrEqual(t, task.Date, time.Now().Format(`20060102`))
+
+	m, err := postJSON("api/task", map[string]any{
+		"id":     id,
+		"date":   task.Date,
+		"title":  "Совет директоров",
+		"repeat": "mw 2 2",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+
+	m, err = postJSON("api/task", map[string]any{
+		"id":     id,
+		"date":   task.Date,
+		"title":  "Совет директоров",
+		"repeat": "mw 9 2",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+}
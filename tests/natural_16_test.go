@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type phrase struct {
+	text   string
+	date   string
+	repeat string
+}
+
+func TestParsePhrase(t *testing.T) {
+	tbl := []phrase{
+		{"", "", ""},
+		{"ooops", "", ""},
+		{"каждый", "", ""},
+		{"every 500 days", "", ""},
+		{"28.01.2024", "", ""},
+		{"через", "", ""},
+		{"сегодня", "20240126", ""},
+		{"Today", "20240126", ""},
+		{"завтра", "20240127", ""},
+		{"tomorrow", "20240127", ""},
+		{"послезавтра", "20240128", ""},
+		{"day after tomorrow", "20240128", ""},
+		{"через 3 дня", "20240129", ""},
+		{"через неделю", "20240202", ""},
+		{"через месяц", "20240226", ""},
+		{"in 2 weeks", "20240209", ""},
+		{"in a year", "20250126", ""},
+		{"в пятницу", "20240202", ""},
+		{"в следующий понедельник", "20240129", ""},
+		{"next friday", "20240202", ""},
+		{"monday", "20240129", ""},
+		{"каждый понедельник", "20240129", "w 1"},
+		{"Каждую пятницу", "20240126", "w 5"},
+		{"по понедельникам и средам", "20240129", "w 1,3"},
+		{"every friday, monday", "20240126", "w 1,5"},
+		{"ежедневно", "20240126", "d 1"},
+		{"every day", "20240126", "d 1"},
+		{"каждые 3 дня", "20240126", "d 3"},
+		{"every other day", "20240126", "d 2"},
+		{"every 2 weeks", "20240126", "d 14"},
+		{"каждую неделю", "20240126", "w 5"},
+		{"every month", "20240126", "m 26"},
+		{"каждые два месяца", "20240126", "FREQ=MONTHLY;INTERVAL=2"},
+		{"ежегодно", "20240126", "y"},
+		{"every weekday", "20240126", "w 1,2,3,4,5"},
+		{"по выходным", "20240127", "w 6,7"},
+		{"каждый рабочий день", "20240126", "bd 1"},
+	}
+	for _, v := range tbl {
+		body, err := getBody("api/parse?now=20240126&text=" + url.QueryEscape(v.text))
+		assert.NoError(t, err)
+
+		var m map[string]string
+		err = json.Unmarshal(body, &m)
+		assert.NoError(t, err)
+
+		if v.date == "" {
+			assert.NotEmpty(t, m["error"], "Ожидается ошибка для %q", v.text)
+			continue
+		}
+		assert.Empty(t, m["error"], v.text)
+		assert.Equal(t, v.date, m["date"], v.text)
+		assert.Equal(t, v.repeat, m["repeat"], v.text)
+	}
+}
+
+func TestAddTaskNatural(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":   "завтра",
+		"title":  "Планёрка",
+		"repeat": "каждый понедельник",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+
+	task, err := db.getTask(m["id"])
+	assert.NoError(t, err)
+	assert.Equal(t, time.Now().AddDate(0, 0, 1).Format(`20060102`), task.Date)
+	assert.Equal(t, "w 1", task.Repeat)
+}
@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type nextDates struct {
+	date   string
+	repeat string
+	count  int
+	want   []string
+}
+
+func TestNextDates(t *testing.T) {
+	tbl := []nextDates{
+		{"20240126", "", 3, []string{}},
+		{"20240126", "d 401", 3, nil},
+		{"20240126", "d 7", 0, nil},
+		{"20240113", "d 7", 3, []string{"20240127", "20240203", "20240210"}},
+		{"20240229", "y", 3, []string{"20250301", "20260301", "20270301"}},
+		{"20240126", "w 1,5", 4, []string{"20240129", "20240202", "20240205", "20240209"}},
+		{"20240126", "m 31", 4, []string{"20240131", "20240331", "20240531", "20240731"}},
+		{"20240126", "m -1 2", 5, []string{"20240229", "20250228", "20260228", "20270228", "20280229"}},
+		{"20240126", "m 29 2", 3, []string{"20240229", "20280229", "20320229"}},
+		{"20240126", "d 7 count 3", 5, []string{"20240202", "20240209"}},
+		{"20240126", "w 1 until 20240212", 5, []string{"20240129", "20240205", "20240212"}},
+		{"20240126", "d 1 until 20240128 count 5", 5, []string{"20240127", "20240128"}},
+		{"20240126", "d 1 count 0", 3, nil},
+		{"20240126", "d 1 until 2024", 3, nil},
+		{"20240126", "d 1 count 2 count 3", 3, nil},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdates?now=20240126&date=%s&repeat=%s&count=%d",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat), v.count)
+		body, err := getBody(urlPath)
+		assert.NoError(t, err)
+
+		var m map[string]any
+		err = json.Unmarshal(body, &m)
+		assert.NoError(t, err)
+
+		if v.want == nil {
+			assert.NotEmpty(t, m["error"], "Ожидается ошибка для %v", v)
+			continue
+		}
+		var got []string
+		dates, _ := m["dates"].([]any)
+		for _, d := range dates {
+			got = append(got, fmt.Sprint(d))
+		}
+		if got == nil {
+			got = []string{}
+		}
+		assert.Equal(t, v.want, got, `{%q, %q, %d}`, v.date, v.repeat, v.count)
+	}
+}
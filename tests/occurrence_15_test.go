@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTaskDate(t *testing.T, id string) string {
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var task map[string]any
+	err = json.Unmarshal(body, &task)
+	assert.NoError(t, err)
+	return fmt.Sprint(task["date"])
+}
+
+func TestSkipTask(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	id := addTask(t, task{
+		date:  day(1),
+		title: "Разовая задача",
+	})
+	ret, err := postJSON("api/task/skip?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task/skip", nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	id = addTask(t, task{
+		date:   day(1),
+		title:  "Стендап",
+		repeat: "d 7",
+	})
+
+	ret, err = postJSON("api/task/skip?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(8), getTaskDate(t, id))
+
+	// Пропущенная дата не возвращается при пересчёте графика.
+	ret, err = postJSON("api/task", map[string]any{
+		"id":     id,
+		"date":   day(-6),
+		"title":  "Стендап",
+		"repeat": "d 7",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(8), getTaskDate(t, id))
+}
+
+func TestMoveTask(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	id := addTask(t, task{
+		date:   day(1),
+		title:  "Ревью",
+		repeat: "d 7",
+	})
+
+	for _, date := range []string{"", "28.01.2024", "20240230"} {
+		ret, err := postJSON("api/task/move?id="+id+"&date="+date, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для даты %q", date)
+	}
+
+	// Дата переноса задаётся в тех же форматах, что и дата задачи.
+	for _, v := range []struct{ date, want string }{
+		{now.AddDate(0, 0, 2).Format("2006-01-02"), day(2)},
+		{day(3), day(3)},
+		{"+4d", day(4)},
+	} {
+		ret, err := postJSON("api/task/move?id="+id+"&date="+url.QueryEscape(v.date), nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+		assert.Equal(t, v.want, getTaskDate(t, id))
+	}
+
+	// После выполнения перенесённого повторения график не сдвигается.
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.Equal(t, day(8), getTaskDate(t, id))
+}
+
+// TestMoveTaskDoneRepeatedly проверяет, что перенос действует только на
+// одно повторение: когда график снова доходит до даты переноса, задача
+// идёт дальше, а не возвращается к исходной дате.
+func TestMoveTaskDoneRepeatedly(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	id := addTask(t, task{
+		date:   day(1),
+		title:  "Полить цветы",
+		repeat: "d 1",
+	})
+	ret, err := postJSON("api/task/move?id="+id+"&date="+day(3), nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	for _, want := range []string{day(2), day(3), day(4), day(5)} {
+		ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+		assert.Equal(t, want, getTaskDate(t, id))
+	}
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+// TestSkipTaskCount проверяет, что пропущенное повторение расходует
+// счётчик "count" так же, как выполненное.
+func TestSkipTaskCount(t *testing.T) {
+	now := time.Now()
+	day := func(n int) string {
+		return now.AddDate(0, 0, n).Format(`20060102`)
+	}
+
+	id := addTask(t, task{
+		date:   day(1),
+		title:  "Курс массажа",
+		repeat: "d 1 count 3",
+	})
+
+	for _, want := range []struct{ date, repeat string }{
+		{day(2), "d 1 count 2"},
+		{day(3), "d 1 count 1"},
+	} {
+		ret, err := postJSON("api/task/skip?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var task map[string]any
+		assert.NoError(t, json.Unmarshal(body, &task))
+		assert.Equal(t, want.date, task["date"])
+		assert.Equal(t, want.repeat, task["repeat"])
+	}
+
+	// Последнее повторение пропустить некуда.
+	ret, err := postJSON("api/task/skip?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+}
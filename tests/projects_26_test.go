@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/handlers"
+	"todo-app/internal/scheduler"
+	"todo-app/internal/storage"
+)
+
+type projectUser struct {
+	id    string
+	token string
+}
+
+// TestProjects проверяет общие проекты: задачи видят только участники,
+// viewer не может их изменять, задачи фильтруются по проекту и исполнителю.
+func TestProjects(t *testing.T) {
+	stores := map[string]interface {
+		storage.TaskRepository
+		storage.UserRepository
+	}{
+		"memory": storage.NewMemoryStorage(),
+	}
+	if sqlite := sqliteStorage(t, "projects.db"); sqlite != nil {
+		stores["sqlite"] = sqlite
+	}
+
+	for name, db := range stores {
+		t.Run(name, func(t *testing.T) {
+			testProjects(t, db, db)
+		})
+	}
+}
+
+func testProjects(t *testing.T, db storage.TaskRepository, users storage.UserRepository) {
+	auth, err := handlers.NewAccountsAuth(users, "secret", nil)
+	assert.NoError(t, err)
+	server := httptest.NewServer(handlers.NewRouter(db, scheduler.SystemClock{}, "../web", auth))
+	defer server.Close()
+
+	call := func(user projectUser, method, path string, body map[string]any) (int, map[string]any) {
+		return authRequest(t, server.URL+path, method, user.token, body)
+	}
+	newUser := func(login string) projectUser {
+		code, m := authRequest(t, server.URL+"/api/register", http.MethodPost, "", map[string]any{
+			"login":    login,
+			"password": login + "-password",
+		})
+		assert.Equal(t, http.StatusOK, code)
+		return projectUser{id: fmt.Sprint(m["id"]), token: fmt.Sprint(m["token"])}
+	}
+	alice, bob, carol, dave := newUser("alice"), newUser("bob"), newUser("carol"), newUser("dave")
+
+	code, m := call(alice, http.MethodPost, "/api/projects", map[string]any{"name": "Команда"})
+	assert.Equal(t, http.StatusOK, code)
+	project := fmt.Sprint(m["id"])
+
+	code, _ = call(bob, http.MethodGet, "/api/project/members?project_id="+project, nil)
+	assert.Equal(t, http.StatusNotFound, code, "не участник")
+
+	for login, role := range map[string]string{"bob": "editor", "carol": "viewer"} {
+		code, _ = call(alice, http.MethodPost, "/api/project/members", map[string]any{
+			"project_id": project, "login": login, "role": role,
+		})
+		assert.Equal(t, http.StatusOK, code, login)
+	}
+	code, _ = call(bob, http.MethodPost, "/api/project/members", map[string]any{
+		"project_id": project, "login": "dave", "role": "viewer",
+	})
+	assert.Equal(t, http.StatusForbidden, code, "editor не управляет участниками")
+
+	code, m = call(carol, http.MethodGet, "/api/projects", nil)
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, m["projects"], 1) {
+		assert.Equal(t, "viewer", m["projects"].([]any)[0].(map[string]any)["role"])
+	}
+
+	code, _ = call(alice, http.MethodPost, "/api/task", map[string]any{
+		"title": "Без исполнителя", "project_id": project, "assignee_id": dave.id,
+	})
+	assert.Equal(t, http.StatusBadRequest, code, "исполнитель не участник")
+
+	code, m = call(alice, http.MethodPost, "/api/task", map[string]any{
+		"title": "Отчёт", "project_id": project, "assignee_id": bob.id,
+	})
+	assert.Equal(t, http.StatusOK, code)
+	task := fmt.Sprint(m["id"])
+
+	code, _ = call(alice, http.MethodPost, "/api/task", map[string]any{"title": "Личная задача Алисы"})
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = call(carol, http.MethodPost, "/api/task", map[string]any{"title": "Задача наблюдателя", "project_id": project})
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, m = call(alice, http.MethodGet, "/api/tasks", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 2)
+	code, m = call(alice, http.MethodGet, "/api/tasks?project="+project, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 1)
+	code, m = call(bob, http.MethodGet, "/api/tasks?assignee=me", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 1)
+	code, m = call(alice, http.MethodGet, "/api/tasks?assignee=me", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 0)
+	code, _ = call(alice, http.MethodGet, "/api/tasks?project=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, m = call(dave, http.MethodGet, "/api/tasks?project="+project, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 0)
+	code, _ = call(dave, http.MethodGet, "/api/task?id="+task, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Наблюдатель видит задачу, но не может её изменить.
+	code, m = call(carol, http.MethodGet, "/api/task?id="+task, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, project, m["project_id"])
+	assert.Equal(t, bob.id, m["assignee_id"])
+	code, _ = call(carol, http.MethodPut, "/api/task", map[string]any{"id": task, "title": "Правка", "date": "20240101"})
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = call(carol, http.MethodPost, "/api/task/done?id="+task, nil)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = call(carol, http.MethodDelete, "/api/task?id="+task, nil)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// Изменение без project_id оставляет задачу в проекте.
+	code, _ = call(bob, http.MethodPut, "/api/task", map[string]any{"id": task, "title": "Отчёт за квартал"})
+	assert.Equal(t, http.StatusOK, code)
+	code, m = call(alice, http.MethodGet, "/api/task?id="+task, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Отчёт за квартал", m["title"])
+	assert.Equal(t, project, m["project_id"])
+	assert.Equal(t, bob.id, m["assignee_id"])
+
+	// История выполненной задачи проекта доступна всем участникам.
+	code, _ = call(bob, http.MethodPost, "/api/task/done?id="+task, nil)
+	assert.Equal(t, http.StatusOK, code)
+	code, m = call(carol, http.MethodGet, "/api/task/history?id="+task, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["completions"], 1)
+	code, _ = call(dave, http.MethodGet, "/api/task/history?id="+task, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Вышедший участник больше не видит задачи проекта.
+	code, _ = call(bob, http.MethodPost, "/api/task", map[string]any{"title": "Ещё задача", "project_id": project})
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = call(carol, http.MethodDelete, "/api/project/members?project_id="+project+"&user_id="+carol.id, nil)
+	assert.Equal(t, http.StatusOK, code)
+	code, m = call(carol, http.MethodGet, "/api/tasks", nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["tasks"], 0)
+	code, _ = call(alice, http.MethodDelete, "/api/project/members?project_id="+project+"&user_id="+alice.id, nil)
+	assert.Equal(t, http.StatusBadRequest, code, "владелец не выходит из проекта")
+}
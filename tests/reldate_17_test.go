@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeDates(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	tbl := []struct {
+		date string
+		want string
+	}{
+		{"today", now.Format(`20060102`)},
+		{"tomorrow", now.AddDate(0, 0, 1).Format(`20060102`)},
+		{"+3d", now.AddDate(0, 0, 3).Format(`20060102`)},
+		{"+1w", now.AddDate(0, 0, 7).Format(`20060102`)},
+		{"+2m", now.AddDate(0, 2, 0).Format(`20060102`)},
+		{now.AddDate(0, 0, 5).Format(`2006-01-02`), now.AddDate(0, 0, 5).Format(`20060102`)},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/task", map[string]any{
+			"date":  v.date,
+			"title": "Задача",
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, m["error"], v.date)
+		id := fmt.Sprint(m["id"])
+
+		task, err := db.getTask(id)
+		assert.NoError(t, err)
+		assert.Equal(t, v.want, task.Date, v.date)
+
+		m, err = postJSON("api/task", map[string]any{
+			"id":    id,
+			"date":  v.date,
+			"title": "Задача",
+		}, http.MethodPut)
+		assert.NoError(t, err)
+		assert.Empty(t, m["error"], v.date)
+	}
+
+	for _, date := range []string{"+d", "++3d", "+3x", "2024-13-01", "28.01.2024"} {
+		m, err := postJSON("api/task", map[string]any{
+			"date":  date,
+			"title": "Задача",
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], "Ожидается ошибка для даты %q", date)
+	}
+
+	for _, now := range []string{"2024-01-26", "20240126"} {
+		body, err := getBody("api/nextdate?now=" + now + "&date=20240113&repeat=d+7")
+		assert.NoError(t, err)
+		assert.Equal(t, "20240127", strings.TrimSpace(string(body)), now)
+	}
+
+	body, err := getBody("api/nextdate?now=today&date=" + now.Format(`20060102`) + "&repeat=d+1")
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), strings.TrimSpace(string(body)))
+}
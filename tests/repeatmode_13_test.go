@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatMode(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	date := now.AddDate(0, 0, 5).Format(`20060102`)
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":        date,
+		"title":       "Полить цветы",
+		"repeat":      "d 3",
+		"repeat_mode": "sometimes",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+
+	tbl := []struct {
+		mode string
+		want string
+	}{
+		{"", now.AddDate(0, 0, 8).Format(`20060102`)},
+		{"fixed", now.AddDate(0, 0, 8).Format(`20060102`)},
+		{"completion", now.AddDate(0, 0, 3).Format(`20060102`)},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/task", map[string]any{
+			"date":        date,
+			"title":       "Полить цветы",
+			"repeat":      "d 3",
+			"repeat_mode": v.mode,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		id := fmt.Sprint(m["id"])
+
+		ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		task, err := db.getTask(id)
+		assert.NoError(t, err)
+		assert.Equal(t, v.want, task.Date, "режим %q", v.mode)
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateRRule(t *testing.T) {
+	tbl := []nextDate{
+		{"20240126", "FREQ=", ""},
+		{"20240126", "INTERVAL=2", ""},
+		{"20240126", "FREQ=HOURLY", ""},
+		{"20240126", "FREQ=WEEKLY;BYSETPOS=1", ""},
+		{"20240126", "FREQ=WEEKLY;BYDAY=2MO", ""},
+		{"20240126", "FREQ=WEEKLY;BYDAY=XX", ""},
+		{"20240126", "FREQ=DAILY;INTERVAL=0", ""},
+		{"20240126", "FREQ=DAILY;COUNT=3;UNTIL=20250101", ""},
+		{"20240126", "FREQ=DAILY;COUNT=1", ""},
+		{"20240126", "FREQ=WEEKLY;UNTIL=20240128T000000Z", ""},
+		{"20240126", "FREQ=DAILY", "20240127"},
+		{"20240126", "FREQ=DAILY;INTERVAL=3", "20240129"},
+		{"20240113", "FREQ=DAILY;INTERVAL=7", "20240127"},
+		{"20240126", "FREQ=WEEKLY", "20240202"},
+		{"20240126", "FREQ=WEEKLY;BYDAY=MO,WE;INTERVAL=2", "20240205"},
+		{"20240101", "FREQ=WEEKLY;BYDAY=MO,WE;INTERVAL=2", "20240129"},
+		{"20240126", "freq=weekly;byday=su", "20240128"},
+		{"20240126", "RRULE:FREQ=MONTHLY;BYDAY=2TU", "20240213"},
+		{"20240126", "FREQ=MONTHLY;BYDAY=-1FR", "20240223"},
+		{"20240131", "FREQ=MONTHLY", "20240331"},
+		{"20240126", "FREQ=MONTHLY;BYMONTHDAY=-1", "20240131"},
+		{"20240115", "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1,15", "20240401"},
+		{"20240126", "FREQ=MONTHLY;BYDAY=FR;BYMONTHDAY=13", "20240913"},
+		{"20240126", "FREQ=YEARLY", "20250126"},
+		{"20240126", "FREQ=YEARLY;BYMONTH=3;BYMONTHDAY=8", "20240308"},
+		{"20240126", "FREQ=YEARLY;BYDAY=-1SU", "20241229"},
+		{"20240126", "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH", "20241128"},
+		{"20240126", "FREQ=WEEKLY;UNTIL=20240202T000000Z", "20240202"},
+		{"20240126", "FREQ=YEARLY;INTERVAL=400;BYMONTH=2;BYMONTHDAY=30", ""},
+		{"20240126", "FREQ=MONTHLY;BYMONTH=4,6;BYMONTHDAY=31,-31", ""},
+		{"20230126", "FREQ=YEARLY;INTERVAL=400;BYMONTH=2;BYMONTHDAY=29", ""},
+		{"20240301", "FREQ=YEARLY;INTERVAL=400;BYMONTH=2;BYMONTHDAY=29", "24240229"},
+		{"20240126", "FREQ=DAILY;INTERVAL=400", "20250301"},
+		{"20240126", "FREQ=MONTHLY;INTERVAL=13;BYMONTH=4;BYMONTHDAY=30", "20270430"},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if len(v.want) == 0 {
+			assert.Error(t, err, "Ожидается ошибка для %v", v)
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
+
+func TestAddTaskRRule(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	repeat := "FREQ=WEEKLY;BYDAY=MO,WE;INTERVAL=2"
+	id := addTask(t, task{
+		date:   "20240101",
+		title:  "Планёрка",
+		repeat: repeat,
+	})
+
+	task, err := db.getTask(id)
+	assert.NoError(t, err)
+	assert.Equal(t, repeat, task.Repeat)
+	assert.GreaterOrEqual(t, task.Date, time.Now().Format(`20060102`))
+}
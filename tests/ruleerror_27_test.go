@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/scheduler"
+)
+
+// TestRuleErrors проверяет, что ParseRule возвращает *RuleError с видом
+// ошибки, доступным через errors.Is, и с полем и значением, в которых она
+// найдена.
+func TestRuleErrors(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		kind   error
+		field  string
+		value  string
+	}{
+		{"", scheduler.ErrEmptyRule, "", ""},
+		{"k 34", scheduler.ErrUnsupportedType, "", "k"},
+		{"y 5", scheduler.ErrInvalidFormat, "yearly rule", ""},
+		{"d", scheduler.ErrInvalidFormat, "daily rule", ""},
+		{"d 401", scheduler.ErrInvalidValue, "number of days", "401"},
+		{"d x", scheduler.ErrInvalidValue, "number of days", "x"},
+		{"bd 0", scheduler.ErrInvalidValue, "number of business days", "0"},
+		{"w", scheduler.ErrInvalidFormat, "weekly rule", ""},
+		{"w 8", scheduler.ErrInvalidValue, "days of week", "8"},
+		{"m 1 2 3", scheduler.ErrInvalidFormat, "monthly rule", ""},
+		{"m 0", scheduler.ErrInvalidValue, "days of month", "0"},
+		{"m 32", scheduler.ErrInvalidValue, "days of month", "32"},
+		{"m 1 13", scheduler.ErrInvalidValue, "months", "13"},
+		{"m 31 2", scheduler.ErrNoOccurrence, "monthly rule", "m 31 2"},
+		{"mw 6 1", scheduler.ErrInvalidValue, "weekday numbers", "6"},
+		{"mw 1 9", scheduler.ErrInvalidValue, "days of week", "9"},
+		{"mw 5 1 2", scheduler.ErrNoOccurrence, "monthly weekday rule", "mw 5 1 2"},
+		{"mw -5 1,7 2", scheduler.ErrNoOccurrence, "monthly weekday rule", "mw -5 1,7 2"},
+		{"d 1 count", scheduler.ErrInvalidFormat, "rule modifier", ""},
+		{"d 1 count 2 count 3", scheduler.ErrInvalidFormat, "rule modifier", "count"},
+		{"d 1 until 2024", scheduler.ErrInvalidValue, "until", "2024"},
+		{"d 1 count -1", scheduler.ErrInvalidValue, "count", "-1"},
+		{"FREQ=HOURLY", scheduler.ErrUnsupportedType, "FREQ", "HOURLY"},
+		{"FREQ=DAILY;INTERVAL=0", scheduler.ErrInvalidValue, "INTERVAL", "0"},
+		{"FREQ=WEEKLY;BYDAY=XX", scheduler.ErrInvalidValue, "BYDAY", "XX"},
+		{"FREQ=DAILY;BYSETPOS=1", scheduler.ErrUnsupportedType, "RRULE", "BYSETPOS"},
+		{"FREQ=DAILY;COUNT=2;UNTIL=20250101", scheduler.ErrInvalidFormat, "RRULE", "COUNT and UNTIL"},
+	}
+
+	kinds := []error{
+		scheduler.ErrEmptyRule, scheduler.ErrUnsupportedType, scheduler.ErrInvalidFormat,
+		scheduler.ErrInvalidValue, scheduler.ErrNoOccurrence,
+	}
+	for _, v := range tbl {
+		_, err := scheduler.ParseRule(v.repeat)
+		if !assert.Error(t, err, v.repeat) {
+			continue
+		}
+
+		var ruleErr *scheduler.RuleError
+		if assert.True(t, errors.As(err, &ruleErr), v.repeat) {
+			assert.Equal(t, v.repeat, ruleErr.Rule, v.repeat)
+			assert.Equal(t, v.field, ruleErr.Field, v.repeat)
+			assert.Equal(t, v.value, ruleErr.Value, v.repeat)
+		}
+
+		// Ошибка относится ровно к одному виду.
+		for _, kind := range kinds {
+			assert.Equal(t, kind == v.kind, errors.Is(err, kind), "%s: %v", v.repeat, kind)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchCase(t *testing.T) {
+	if !Search {
+		t.Skip("search is disabled in settings")
+	}
+
+	date := time.Now().AddDate(0, 0, 1).Format(`20060102`)
+	ids := []string{
+		addTask(t, task{
+			date:    date,
+			title:   "Купить Ёлочные игрушки",
+			comment: "В МАГАЗИНЕ у дома",
+		}),
+		addTask(t, task{
+			date:  date,
+			title: "Скидка 50% на ёлочные гирлянды",
+		}),
+	}
+
+	tbl := []struct {
+		search string
+		count  int
+	}{
+		{"ёлочные", 2},
+		{"ЁЛОЧНЫЕ ИГРУШКИ", 1},
+		{"магазине", 1},
+		{"50%", 1},
+		{"5_%", 0},
+		{"гирлянды на ёлку", 0},
+	}
+	for _, v := range tbl {
+		tasks := getTasks(t, url.QueryEscape(v.search))
+		assert.Len(t, tasks, v.count, v.search)
+	}
+
+	for _, id := range ids {
+		ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+}
@@ -2,6 +2,8 @@ package tests
 
 var Port = 7540
 var DBFile = "../storage/scheduler.db"
-var FullNextDate = false
-var Search = false
+var DatabaseURL = ``
+var InMemory = false
+var FullNextDate = true
+var Search = true
 var Token = ``
@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"todo-app/internal/storage"
+)
+
+type repository interface {
+	storage.TaskRepository
+	storage.UserRepository
+}
+
+// TestStorage проверяет хранилища напрямую, без сервера. SQLite
+// проверяется в сборке с CGO, PostgreSQL — только если задан
+// TODO_DATABASE_URL.
+func TestStorage(t *testing.T) {
+	stores := map[string]repository{
+		"memory": storage.NewMemoryStorage(),
+	}
+	if sqlite := sqliteStorage(t, "storage.db"); sqlite != nil {
+		stores["sqlite"] = sqlite
+	}
+	if databaseURL := os.Getenv("TODO_DATABASE_URL"); databaseURL != "" {
+		postgres, err := storage.NewPostgresStorage(databaseURL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer postgres.Close()
+		stores["postgres"] = postgres
+	}
+
+	for name, db := range stores {
+		t.Run(name, func(t *testing.T) {
+			testStorage(t, db)
+		})
+	}
+}
+
+func testStorage(t *testing.T, db repository) {
+	// Пользователи с уникальными логинами изолируют тест от задач в общей базе.
+	suffix := time.Now().Format("150405.000000000")
+	owners := make([]storage.TaskRepository, 2)
+	for i := range owners {
+		id, err := db.AddUser(&storage.User{
+			Login:        fmt.Sprintf("storage-%d-%s", i, suffix),
+			PasswordHash: "-",
+			CreatedAt:    time.Now().Format(time.RFC3339),
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+		owners[i] = db.ForOwner(id)
+	}
+	alice, bob := owners[0], owners[1]
+
+	id, err := alice.AddTask(&storage.Task{
+		Date:       "20240126",
+		Time:       "09:30",
+		Title:      "Купить Молоко",
+		Comment:    "ЁЛКА и 50% скидка",
+		Repeat:     "d 1",
+		RepeatMode: "fixed",
+		CatchUp:    "oldest",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer alice.DeleteTask(id)
+
+	task, err := alice.GetTaskByID(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Купить Молоко", task.Title)
+		assert.Equal(t, "09:30", task.Time)
+	}
+	_, err = bob.GetTaskByID(id)
+	assert.ErrorIs(t, err, storage.ErrTaskNotFound)
+
+	// Поиск не учитывает регистр кириллицы и не считает % шаблоном.
+	for search, want := range map[string]int{
+		"молоко":  1,
+		"КУПИТЬ":  1,
+		"ёлка":    1,
+		"50%":     1,
+		"0%с":     0,
+		"кефир":   0,
+		"Молоко ": 0,
+	} {
+		tasks, err := alice.SearchTasks(search, storage.TaskFilter{}, 10)
+		assert.NoError(t, err)
+		assert.Len(t, tasks, want, search)
+	}
+	tasks, err := bob.SearchTasks("молоко", storage.TaskFilter{}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 0)
+
+	tasks, err = alice.GetTasksByDate("20240126", storage.TaskFilter{}, 10)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	task.Title = "Купить кефир"
+	assert.NoError(t, alice.UpdateTask(task))
+	assert.NoError(t, bob.UpdateTask(&storage.Task{ID: id, Title: "Чужая", Date: "20240101"}))
+	assert.NoError(t, alice.UpdateTaskDate(id, "20240127", "d 2"))
+	task, err = alice.GetTaskByID(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Купить кефир", task.Title)
+		assert.Equal(t, "20240127", task.Date)
+		assert.Equal(t, "d 2", task.Repeat)
+	}
+
+	assert.NoError(t, alice.AddException(id, "20240129", "20240130"))
+	assert.NoError(t, alice.AddException(id, "20240128", ""))
+	exceptions, err := alice.GetExceptions(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []storage.Exception{{Date: "20240128"}, {Date: "20240129", MovedTo: "20240130"}}, exceptions)
+	assert.NoError(t, alice.ResolveMove(id, "20240129"))
+	exceptions, err = alice.GetExceptions(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []storage.Exception{{Date: "20240128"}, {Date: "20240129"}}, exceptions)
+
+	// Выполнение переносит задачу, а последнее — удаляет её.
+	assert.ErrorIs(t, bob.CompleteTask(storage.Completion{TaskID: id, Date: "20240127"}, "20240129", "d 2"), storage.ErrTaskNotFound)
+	assert.NoError(t, alice.CompleteTask(storage.Completion{TaskID: id, Date: "20240127", CompletedAt: "2024-01-27T10:00:00Z"}, "20240131", "d 2 count 1"))
+	task, err = alice.GetTaskByID(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "20240131", task.Date)
+		assert.Equal(t, "d 2 count 1", task.Repeat)
+	}
+	assert.NoError(t, alice.CompleteTask(storage.Completion{TaskID: id, Date: "20240131", CompletedAt: "2024-01-31T10:00:00Z", Note: "готово"}, "", ""))
+	exists, err := alice.TaskExists(id)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+	exceptions, err = alice.GetExceptions(id)
+	assert.NoError(t, err)
+	assert.Empty(t, exceptions)
+
+	// История выполнений сохраняется после удаления задачи.
+	completions, err := alice.GetCompletions(id)
+	assert.NoError(t, err)
+	if assert.Len(t, completions, 2) {
+		assert.Equal(t, "готово", completions[0].Note)
+	}
+	completions, err = bob.GetCompletions(id)
+	assert.NoError(t, err)
+	assert.Len(t, completions, 0)
+
+	id, err = alice.AddTask(&storage.Task{Date: "20240126", Title: "Разовая"})
+	if assert.NoError(t, err) {
+		assert.ErrorIs(t, bob.DeleteTask(id), storage.ErrTaskNotFound)
+		assert.NoError(t, alice.DeleteTask(id))
+		exists, err = alice.TaskExists(id)
+		assert.NoError(t, err)
+		assert.False(t, exists)
+	}
+}
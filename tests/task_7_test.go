@@ -44,8 +44,7 @@ func TestDone(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Empty(t, ret)
 
-		var task Task
-		err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		task, err := db.getTask(id)
 		assert.NoError(t, err)
 		now = now.AddDate(0, 0, 3)
 		assert.Equal(t, task.Date, now.Format(`20060102`))
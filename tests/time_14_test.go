@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTime(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+
+	for _, v := range []string{"25:00", "9:30", "18-30", "18:30:00"} {
+		m, err := postJSON("api/task", map[string]any{
+			"date":  now.Format(`20060102`),
+			"time":  v,
+			"title": "Созвон",
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], "Ожидается ошибка для времени %q", v)
+	}
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":  now.Format(`20060102`),
+		"time":  "18:30",
+		"title": "Созвон",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(m["id"])
+
+	task, err := db.getTask(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "18:30", task.Time)
+
+	m, err = postJSON("api/task", map[string]any{
+		"id":    id,
+		"date":  now.Format(`20060102`),
+		"time":  "",
+		"title": "Созвон",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+
+	task, err = db.getTask(id)
+	assert.NoError(t, err)
+	assert.Equal(t, "", task.Time)
+}
+
+func TestTaskTimezone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	m, err := postJSON("api/task?tz=Mars/Olympus", map[string]any{
+		"title": "Созвон",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+
+	for _, tz := range []string{"Pacific/Kiritimati", "Pacific/Pago_Pago"} {
+		loc, err := time.LoadLocation(tz)
+		assert.NoError(t, err)
+
+		m, err := postJSON("api/task?tz="+tz, map[string]any{
+			"title": "Созвон",
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		id := fmt.Sprint(m["id"])
+
+		task, err := db.getTask(id)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Now().In(loc).Format(`20060102`), task.Date, tz)
+	}
+}
+
+// TestUpdateKeepsFields проверяет, что изменение задачи без time,
+// repeat_mode и catchup сохраняет их прежние значения.
+func TestUpdateKeepsFields(t *testing.T) {
+	m, err := postJSON("api/task", map[string]any{
+		"time":        "09:15",
+		"title":       "Отчёт",
+		"repeat":      "d 7",
+		"repeat_mode": "completion",
+		"catchup":     "each",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id := fmt.Sprint(m["id"])
+
+	m, err = postJSON("api/task", map[string]any{
+		"id":     id,
+		"date":   time.Now().Format(`20060102`),
+		"title":  "Отчёт за неделю",
+		"repeat": "d 7",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+
+	body, err := getBody("api/task?id=" + id)
+	assert.NoError(t, err)
+	var task map[string]any
+	assert.NoError(t, json.Unmarshal(body, &task))
+	assert.Equal(t, "Отчёт за неделю", task["title"])
+	assert.Equal(t, "09:15", task["time"])
+	assert.Equal(t, "completion", task["repeat_mode"])
+	assert.Equal(t, "each", task["catchup"])
+
+	m, err = postJSON("api/task", map[string]any{
+		"id":          id,
+		"date":        time.Now().Format(`20060102`),
+		"time":        "",
+		"title":       "Отчёт за неделю",
+		"repeat":      "d 7",
+		"repeat_mode": "",
+		"catchup":     "",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+
+	body, err = getBody("api/task?id=" + id)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(body, &task))
+	assert.Equal(t, "", task["time"])
+	assert.Equal(t, "fixed", task["repeat_mode"])
+	assert.Equal(t, "oldest", task["catchup"])
+
+	_, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+}